@@ -0,0 +1,175 @@
+package sq
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/userhubdev/sq/internal/builder"
+)
+
+// Statement is a SQL statement with its args, see InsertBuilder.ToSqlBatches.
+type Statement struct {
+	SQL  string
+	Args []any
+}
+
+// ToSql implements the Sqlizer interface.
+func (s Statement) ToSql() (string, []any, error) {
+	return s.SQL, s.Args, nil
+}
+
+// ToSqlBatches splits the rows of the query into statements which each have
+// at most maxParams parameters, for inserting more rows than the database
+// allows parameters in one statement. Each statement has the prefixes,
+// options, suffixes, ON CONFLICT and RETURNING clauses of the query.
+//
+// If maxParams is 0, the limit of the Dialect is used, e.g. 65535 for
+// Postgres. Without a Dialect, the query is not split.
+//
+// The statements also keep to the row limit of the Dialect: 1000 rows for SQL
+// Server, and a single row for Oracle, which has no multi-row VALUES.
+//
+// Ex:
+//
+//	Insert("users").Columns("name", "age").Values("a", 1).Values("b", 2).Values("c", 3).ToSqlBatches(4)
+//	// INSERT INTO users (name,age) VALUES (?,?),(?,?)
+//	// INSERT INTO users (name,age) VALUES (?,?)
+func (b InsertBuilder) ToSqlBatches(maxParams int) ([]Statement, error) {
+	data := builder.GetStruct(b).(insertData)
+	return data.toSqlBatches(maxParams)
+}
+
+// ExecBatches executes the statements of ToSqlBatches with the Runner set by
+// RunWith, and returns the total number of rows affected.
+//
+// The statements are executed in a transaction if the Runner can begin one,
+// as *sql.DB and *sql.Conn can. With a *sql.Tx, they are executed in it.
+func (b InsertBuilder) ExecBatches(ctx context.Context, maxParams int) (int64, error) {
+	data := builder.GetStruct(b).(insertData)
+	return data.execBatches(ctx, maxParams)
+}
+
+func (d *insertData) toSqlBatches(maxParams int) ([]Statement, error) {
+	if maxParams < 0 {
+		return nil, fmt.Errorf("invalid maximum number of parameters %d", maxParams)
+	}
+	if d.Select != nil {
+		return nil, errors.New("insert statements with a select clause cannot be split into batches")
+	}
+
+	rc := renderContext{dialect: d.Dialect, format: d.PlaceholderFormat}
+	if maxParams == 0 {
+		maxParams = rc.maxParams()
+	}
+	maxRows := rc.maxRows()
+	if (maxParams == 0 && maxRows == 0) || len(d.Values) <= 1 {
+		return d.appendBatch(nil, d.Values)
+	}
+
+	rowParams := make([]int, len(d.Values))
+	for i, row := range d.Values {
+		for _, val := range row {
+			if vs, ok := val.(Sqlizer); ok {
+				_, vargs, err := nestedToSql(rc, vs)
+				if err != nil {
+					return nil, err
+				}
+				rowParams[i] += len(vargs)
+			} else {
+				rowParams[i]++
+			}
+		}
+	}
+
+	// the parameters of the other clauses, which every statement has
+	first := *d
+	first.Values = d.Values[:1]
+	_, args, err := first.toSqlRaw(rc)
+	if err != nil {
+		return nil, err
+	}
+	fixed := len(args) - rowParams[0]
+
+	var stmts []Statement
+	start, params := 0, fixed
+	for i, n := range rowParams {
+		if maxParams > 0 && fixed+n > maxParams {
+			return nil, fmt.Errorf("insert row %d has %d parameters, more than the maximum of %d", i, fixed+n, maxParams)
+		}
+		if (maxParams > 0 && params+n > maxParams) || (maxRows > 0 && i-start == maxRows) {
+			if stmts, err = d.appendBatch(stmts, d.Values[start:i]); err != nil {
+				return nil, err
+			}
+			start, params = i, fixed
+		}
+		params += n
+	}
+
+	return d.appendBatch(stmts, d.Values[start:])
+}
+
+// appendBatch appends the statement which inserts rows to stmts.
+func (d *insertData) appendBatch(stmts []Statement, rows [][]any) ([]Statement, error) {
+	batch := *d
+	batch.Values = rows
+
+	sql, args, err := batch.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return append(stmts, Statement{SQL: sql, Args: args}), nil
+}
+
+// txBeginner is implemented by Runners which can begin a transaction.
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+func (d *insertData) execBatches(ctx context.Context, maxParams int) (int64, error) {
+	if d.RunWith == nil {
+		return 0, ErrRunnerNotSet
+	}
+
+	stmts, err := d.toSqlBatches(maxParams)
+	if err != nil {
+		return 0, err
+	}
+
+	runner := d.RunWith
+	var tx *sql.Tx
+	if db, ok := runner.(txBeginner); ok && len(stmts) > 1 {
+		if tx, err = db.BeginTx(ctx, nil); err != nil {
+			return 0, err
+		}
+		runner = tx
+	}
+
+	var affected int64
+	for _, stmt := range stmts {
+		n, err := execAffected(ctx, runner, stmt)
+		if err != nil {
+			if tx != nil {
+				_ = tx.Rollback()
+			}
+			return 0, err
+		}
+		affected += n
+	}
+
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return 0, err
+		}
+	}
+	return affected, nil
+}
+
+func execAffected(ctx context.Context, runner Runner, stmt Statement) (int64, error) {
+	res, err := runner.ExecContext(ctx, stmt.SQL, stmt.Args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
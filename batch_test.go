@@ -0,0 +1,144 @@
+package sq
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertToSqlBatches(t *testing.T) {
+	b := Insert("users").
+		Prefix("/* batch */").
+		Options("IGNORE").
+		Columns("name", "age").
+		Values("a", 1).
+		Values("b", Expr("? + ?", 1, 1)).
+		Values("c", 3).
+		Values("d", 4).
+		Suffix("-- end").
+		PlaceholderFormat(Dollar)
+
+	stmts, err := b.ToSqlBatches(5)
+	require.NoError(t, err)
+	require.Equal(t, []Statement{
+		{
+			SQL:  "/* batch */ INSERT IGNORE INTO users (name,age) VALUES ($1,$2),($3,$4 + $5) -- end",
+			Args: []any{"a", 1, "b", 1, 1},
+		},
+		{
+			SQL:  "/* batch */ INSERT IGNORE INTO users (name,age) VALUES ($1,$2),($3,$4) -- end",
+			Args: []any{"c", 3, "d", 4},
+		},
+	}, stmts)
+
+	// Without a limit, the query is not split.
+	stmts, err = b.ToSqlBatches(0)
+	require.NoError(t, err)
+	require.Len(t, stmts, 1)
+
+	_, _, err = b.ToSql()
+	require.NoError(t, err)
+}
+
+func TestInsertToSqlBatchesUpsert(t *testing.T) {
+	b := Insert("users").Columns("id", "name").
+		Values(1, "a").
+		Values(2, "b").
+		Values(3, "c").
+		OnConflict("id").
+		DoUpdateSet("name", Expr("EXCLUDED.name")).
+		DoUpdateWhere(Eq{"users.locked": false}).
+		Returning("id").
+		Dialect(Postgres)
+
+	stmts, err := b.ToSqlBatches(5)
+	require.NoError(t, err)
+	require.Equal(t, []Statement{
+		{
+			SQL: "INSERT INTO users (id,name) VALUES ($1,$2),($3,$4) " +
+				"ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name WHERE users.locked = $5 RETURNING id",
+			Args: []any{1, "a", 2, "b", false},
+		},
+		{
+			SQL: "INSERT INTO users (id,name) VALUES ($1,$2) " +
+				"ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name WHERE users.locked = $3 RETURNING id",
+			Args: []any{3, "c", false},
+		},
+	}, stmts)
+
+	_, err = b.ToSqlBatches(2)
+	require.EqualError(t, err, "insert row 0 has 3 parameters, more than the maximum of 2")
+}
+
+func TestInsertToSqlBatchesDialectDefault(t *testing.T) {
+	b := Insert("t").Columns("a").Dialect(SQLServer)
+	for i := 0; i < 2100; i++ {
+		b = b.Values(i)
+	}
+
+	stmts, err := b.ToSqlBatches(0)
+	require.NoError(t, err)
+	require.Len(t, stmts, 3)
+	require.Len(t, stmts[0].Args, 1000)
+	require.Len(t, stmts[1].Args, 1000)
+	require.Len(t, stmts[2].Args, 100)
+
+	// the parameter limit is hit first
+	b = Insert("t").Columns("a", "b", "c").Dialect(SQLServer)
+	for i := 0; i < 1000; i++ {
+		b = b.Values(i, i, i)
+	}
+
+	stmts, err = b.ToSqlBatches(0)
+	require.NoError(t, err)
+	require.Len(t, stmts, 2)
+	require.Len(t, stmts[0].Args, 2097)
+	require.Len(t, stmts[1].Args, 903)
+}
+
+func TestInsertToSqlBatchesOracle(t *testing.T) {
+	b := Insert("t").Columns("a", "b").Values(1, "x").Values(2, "y").Dialect(Oracle)
+
+	_, _, err := b.ToSql()
+	require.EqualError(t, err, "multi-row VALUES is not supported by Oracle")
+
+	stmts, err := b.ToSqlBatches(0)
+	require.NoError(t, err)
+	require.Equal(t, []Statement{
+		{SQL: "INSERT INTO t (a,b) VALUES (:1,:2)", Args: []any{1, "x"}},
+		{SQL: "INSERT INTO t (a,b) VALUES (:1,:2)", Args: []any{2, "y"}},
+	}, stmts)
+}
+
+func TestInsertToSqlBatchesErrors(t *testing.T) {
+	_, err := Insert("t").Select(Select("a").From("b")).ToSqlBatches(10)
+	require.EqualError(t, err, "insert statements with a select clause cannot be split into batches")
+
+	_, err = Insert("t").Values(1).ToSqlBatches(-1)
+	require.EqualError(t, err, "invalid maximum number of parameters -1")
+
+	_, err = Insert("t").ToSqlBatches(10)
+	require.EqualError(t, err, "insert statements must have at least one set of values or select clause")
+}
+
+func TestInsertExecBatches(t *testing.T) {
+	db, fake := newFakeDB(nil)
+
+	n, err := Insert("t").Columns("a", "b").
+		Values(1, 2).
+		Values(3, 4).
+		Values(5, 6).
+		RunWith(db).
+		ExecBatches(context.Background(), 4)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+	require.Equal(t, []string{
+		"INSERT INTO t (a,b) VALUES (?,?),(?,?)",
+		"INSERT INTO t (a,b) VALUES (?,?)",
+		"COMMIT",
+	}, fake.queries)
+
+	_, err = Insert("t").Values(1).ExecBatches(context.Background(), 0)
+	require.Equal(t, ErrRunnerNotSet, err)
+}
@@ -8,6 +8,7 @@ type Builder struct {
 	strings.Builder
 	args []any
 	err  error
+	rc   renderContext
 }
 
 // WriteSql converts Sqlizer to SQL strings and writes it to strings.Builder
@@ -18,7 +19,7 @@ func (b *Builder) WriteSql(item Sqlizer) {
 
 	var str string
 	var args []any
-	str, args, b.err = nestedToSql(item)
+	str, args, b.err = nestedToSql(b.rc, item)
 
 	if b.err != nil {
 		return
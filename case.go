@@ -29,13 +29,17 @@ type caseData struct {
 
 // ToSql implements Sqlizer
 func (d *caseData) ToSql() (sqlStr string, args []any, err error) {
+	return d.toSqlContext(renderContext{})
+}
+
+func (d *caseData) toSqlContext(rc renderContext) (sqlStr string, args []any, err error) {
 	if len(d.WhenParts) == 0 {
 		err = errors.New("case expression must contain at lease one WHEN clause")
 
 		return
 	}
 
-	sql := Builder{}
+	sql := Builder{rc: rc}
 
 	sql.WriteString("CASE")
 	if d.What != nil {
@@ -68,6 +72,11 @@ func (b CaseBuilder) ToSql() (string, []any, error) {
 	return data.ToSql()
 }
 
+func (b CaseBuilder) toSqlContext(rc renderContext) (string, []any, error) {
+	data := builder.GetStruct(b).(caseData)
+	return data.toSqlContext(rc)
+}
+
 // what sets optional value for CASE construct "CASE [value] ..."
 func (b CaseBuilder) what(expr any) CaseBuilder {
 	return builder.Set(b, "What", newPart(expr)).(CaseBuilder)
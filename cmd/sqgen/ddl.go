@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var createTableRe = regexp.MustCompile(`(?is)^CREATE\s+(?:(?:GLOBAL|LOCAL)\s+)?(?:(?:TEMP|TEMPORARY|UNLOGGED)\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([^\s(]+)\s*\(`)
+
+// tableConstraints are the first words of the elements of a CREATE TABLE
+// statement which aren't columns.
+var tableConstraints = map[string]bool{
+	"CONSTRAINT": true, "PRIMARY": true, "UNIQUE": true, "FOREIGN": true,
+	"CHECK": true, "KEY": true, "INDEX": true, "EXCLUDE": true, "LIKE": true,
+	"FULLTEXT": true, "SPATIAL": true, "PERIOD": true,
+}
+
+// columnConstraints are the words which end the type of a column definition.
+var columnConstraints = map[string]bool{
+	"NOT": true, "NULL": true, "PRIMARY": true, "DEFAULT": true,
+	"REFERENCES": true, "UNIQUE": true, "CHECK": true, "CONSTRAINT": true,
+	"GENERATED": true, "COLLATE": true, "AUTO_INCREMENT": true,
+	"AUTOINCREMENT": true, "IDENTITY": true, "COMMENT": true, "AS": true,
+}
+
+// sqlTypes maps SQL column types to Go types.
+var sqlTypes = map[string]string{
+	"bool": "bool", "boolean": "bool", "bit": "bool",
+
+	"smallint": "int16", "int2": "int16", "tinyint": "int16", "smallserial": "int16",
+	"int": "int32", "integer": "int32", "int4": "int32", "mediumint": "int32", "serial": "int32", "serial4": "int32",
+	"bigint": "int64", "int8": "int64", "bigserial": "int64", "serial8": "int64",
+
+	"real": "float32", "float4": "float32",
+	"double": "float64", "double precision": "float64", "float8": "float64", "float": "float64",
+
+	// exact numbers are kept as strings to avoid losing precision
+	"numeric": "string", "decimal": "string", "money": "string", "number": "string",
+
+	"text": "string", "varchar": "string", "character varying": "string", "char": "string",
+	"character": "string", "citext": "string", "uuid": "string", "nvarchar": "string",
+	"nchar": "string", "ntext": "string", "clob": "string", "varchar2": "string",
+	"nvarchar2": "string", "tinytext": "string", "mediumtext": "string", "longtext": "string",
+	"enum": "string", "inet": "string", "cidr": "string", "uniqueidentifier": "string",
+
+	"date": "time.Time", "time": "time.Time", "timetz": "time.Time", "timestamp": "time.Time",
+	"timestamptz": "time.Time", "datetime": "time.Time", "datetime2": "time.Time",
+	"datetimeoffset": "time.Time", "smalldatetime": "time.Time",
+	"time with time zone": "time.Time", "time without time zone": "time.Time",
+	"timestamp with time zone": "time.Time", "timestamp without time zone": "time.Time",
+
+	"bytea": "[]byte", "blob": "[]byte", "binary": "[]byte", "varbinary": "[]byte",
+	"tinyblob": "[]byte", "mediumblob": "[]byte", "longblob": "[]byte", "raw": "[]byte",
+
+	"json": "json.RawMessage", "jsonb": "json.RawMessage",
+}
+
+var typeArgsRe = regexp.MustCompile(`\s*\([^)]*\)`)
+
+// parseDDL parses the CREATE TABLE statements of a SQL script. Other
+// statements are ignored.
+func parseDDL(src string) (*schema, error) {
+	s := &schema{}
+
+	for _, stmt := range splitTopLevel(stripComments(src), ';') {
+		stmt = strings.TrimSpace(stmt)
+		m := createTableRe.FindStringSubmatchIndex(stmt)
+		if m == nil {
+			continue
+		}
+
+		name := unquoteName(stmt[m[2]:m[3]])
+		body := stmt[m[1]:]
+		end := strings.LastIndex(body, ")")
+		if end < 0 {
+			return nil, fmt.Errorf("table %s: missing closing parenthesis", name)
+		}
+
+		t := table{name: name, goName: goName(name[strings.LastIndex(name, ".")+1:])}
+		for _, def := range splitTopLevel(body[:end], ',') {
+			fields := strings.Fields(def)
+			if len(fields) == 0 || tableConstraints[strings.ToUpper(fields[0])] {
+				continue
+			}
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("table %s: column %s has no type", name, fields[0])
+			}
+
+			colName := unquoteName(fields[0])
+			t.columns = append(t.columns, column{
+				name:   colName,
+				goName: goName(colName),
+				goType: s.columnType(fields[1:]),
+			})
+		}
+
+		s.tables = append(s.tables, t)
+	}
+
+	if len(s.tables) == 0 {
+		return nil, fmt.Errorf("no CREATE TABLE statements found")
+	}
+	return s, nil
+}
+
+// columnType returns the Go type of a column from the words of its
+// definition following its name.
+func (s *schema) columnType(words []string) string {
+	var typeWords []string
+	for _, w := range words {
+		if columnConstraints[strings.ToUpper(w)] {
+			break
+		}
+		typeWords = append(typeWords, w)
+	}
+
+	def := strings.ToUpper(" " + strings.Join(words, " ") + " ")
+	notNull := strings.Contains(def, " NOT NULL ") || strings.Contains(def, " PRIMARY KEY ")
+
+	sqlType := strings.ToLower(typeArgsRe.ReplaceAllString(strings.Join(typeWords, " "), ""))
+	isArray := strings.HasSuffix(sqlType, "[]")
+	sqlType = strings.TrimSpace(strings.TrimRight(sqlType, "[]"))
+	if i := strings.Index(sqlType, " unsigned"); i >= 0 {
+		sqlType = sqlType[:i]
+	}
+
+	goType, ok := sqlTypes[sqlType]
+	if !ok {
+		return "any"
+	}
+
+	if i := strings.LastIndex(goType, "."); i >= 0 {
+		switch goType[:i] {
+		case "time":
+			s.addImport("time", "")
+		case "json":
+			s.addImport("encoding/json", "")
+		}
+	}
+
+	switch {
+	case isArray:
+		return "[]" + goType
+	case notNull || goType == "[]byte" || goType == "json.RawMessage":
+		return goType
+	default:
+		return "*" + goType
+	}
+}
+
+// stripComments removes -- and /* */ comments outside of quotes.
+func stripComments(src string) string {
+	var sb strings.Builder
+	var quote byte
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '-' && i+1 < len(src) && src[i+1] == '-':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return sb.String()
+			}
+			i += end + 3
+			sb.WriteByte(' ')
+			continue
+		}
+		if i < len(src) {
+			sb.WriteByte(src[i])
+		}
+	}
+	return sb.String()
+}
+
+// splitTopLevel splits src on sep, ignoring separators inside parentheses
+// and quotes.
+func splitTopLevel(src string, sep byte) []string {
+	var parts []string
+	var quote byte
+	depth, start := 0, 0
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, src[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, src[start:])
+}
+
+// unquoteName removes the quotes of a (possibly schema qualified) name, e.g.
+// "public"."users" or `users`.
+func unquoteName(name string) string {
+	return strings.NewReplacer(`"`, "", "`", "", "[", "", "]", "").Replace(name)
+}
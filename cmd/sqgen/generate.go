@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// schema is the set of tables to generate definitions for.
+type schema struct {
+	pkg string
+	// imports maps the import paths used by column types to their names, or
+	// "" for the default name.
+	imports map[string]string
+	tables  []table
+}
+
+type table struct {
+	name    string
+	goName  string
+	columns []column
+}
+
+type column struct {
+	name   string
+	goName string
+	goType string
+}
+
+// addImport records an import path used by a column type, with the name it
+// is imported as, or "" for the default name.
+func (s *schema) addImport(path, name string) {
+	if s.imports == nil {
+		s.imports = make(map[string]string)
+	}
+	s.imports[path] = name
+}
+
+var tmpl = template.Must(template.New("").Parse(`// Code generated by sqgen. DO NOT EDIT.
+
+package {{.Pkg}}
+
+import (
+{{- range .Imports}}
+	{{if .Name}}{{.Name}} {{end}}"{{.Path}}"
+{{- end}}
+
+	"github.com/userhubdev/sq"
+)
+{{range .Tables}}
+// {{.Type}} is the {{.Name}} table.
+type {{.Type}} struct {
+	sq.Table
+{{- range .Columns}}
+	{{.Field}} sq.Column[{{.Type}}]
+{{- end}}
+}
+
+// {{.Var}} is the {{.Name}} table.
+var {{.Var}} = new{{.Type}}("")
+
+func new{{.Type}}(alias string) {{.Type}} {
+	tbl := sq.NewTable("{{.Name}}"{{range .Columns}}, "{{.Name}}"{{end}}).As(alias)
+	return {{.Type}}{
+		Table: tbl,
+{{- range .Columns}}
+		{{.Field}}: sq.NewColumn[{{.Type}}](tbl, "{{.Name}}"),
+{{- end}}
+	}
+}
+
+// As returns the table with an alias, which qualifies its columns.
+func (t {{.Type}}) As(alias string) {{.Type}} {
+	return new{{.Type}}(alias)
+}
+{{end}}`))
+
+// reservedFields are the names which a column field can't use, as they are
+// taken by the generated struct: the embedded sq.Table and its methods, which
+// the field would hide.
+var reservedFields = map[string]bool{
+	"Table":   true,
+	"As":      true,
+	"Name":    true,
+	"Ref":     true,
+	"Columns": true,
+	"All":     true,
+	"String":  true,
+	"ToSql":   true,
+}
+
+// generate renders the Go code declaring the tables of s.
+func generate(s *schema) ([]byte, error) {
+	type importData struct{ Name, Path string }
+	type columnData struct{ Name, Field, Type string }
+	type tableData struct {
+		Name, Type, Var string
+		Columns         []columnData
+	}
+	data := struct {
+		Pkg     string
+		Imports []importData
+		Tables  []tableData
+	}{Pkg: s.pkg}
+
+	for path, name := range s.imports {
+		data.Imports = append(data.Imports, importData{Name: name, Path: path})
+	}
+	sort.Slice(data.Imports, func(i, j int) bool {
+		return data.Imports[i].Path < data.Imports[j].Path
+	})
+
+	seen := make(map[string]bool)
+	for _, t := range s.tables {
+		if len(t.columns) == 0 {
+			return nil, fmt.Errorf("table %s has no columns", t.name)
+		}
+		if seen[t.goName] {
+			return nil, fmt.Errorf("duplicate table %s", t.goName)
+		}
+		seen[t.goName] = true
+
+		td := tableData{Name: t.name, Type: t.goName + "Table", Var: t.goName}
+		for _, c := range t.columns {
+			field := c.goName
+			if reservedFields[field] {
+				field += "Col"
+			}
+			td.Columns = append(td.Columns, columnData{Name: c.name, Field: field, Type: c.goType})
+		}
+		data.Tables = append(data.Tables, td)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return code, nil
+}
+
+// commonInitialisms are the words which are upper-cased in Go names, as in
+// golint.
+var commonInitialisms = map[string]bool{
+	"API": true, "DB": true, "HTML": true, "HTTP": true, "HTTPS": true,
+	"ID": true, "IP": true, "JSON": true, "SQL": true, "URI": true,
+	"URL": true, "UUID": true, "XML": true,
+}
+
+// goName converts a snake_case SQL name to an exported Go name, e.g. user_id
+// to UserID.
+func goName(name string) string {
+	var sb strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if upper := strings.ToUpper(word); commonInitialisms[upper] {
+			sb.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+
+	s := sb.String()
+	if len(s) == 0 || unicode.IsDigit(rune(s[0])) {
+		s = "X" + s
+	}
+	return s
+}
+
+// snakeCase converts a Go name to snake_case, e.g. UserID to user_id.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && !unicode.IsUpper(runes[i-1])
+			nextLower := i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || nextLower {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,94 @@
+// Command sqgen generates typed sq.Table and sq.Column definitions from SQL
+// DDL or Go structs, so that queries referring to renamed or retyped columns
+// fail to compile instead of failing at runtime.
+//
+// Usage:
+//
+//	sqgen -in schema.sql -pkg db -out tables_gen.go
+//	sqgen -in models.go -out tables_gen.go
+//
+// For a .sql file, every CREATE TABLE statement declares a table, and column
+// types are mapped to Go types (nullable columns to pointers). For a .go file,
+// every struct with db tagged fields (see sq.SetStruct) declares a table named
+// after the struct in snake_case, unless its doc comment has a line like
+//
+//	sqgen:table users
+//
+// The generated code declares for each table a variable (e.g. Users) of a
+// struct type (e.g. UsersTable) embedding sq.Table, with a field per column
+// and an As method returning an aliased copy. Column fields which would hide a
+// field or method of sq.Table get a Col suffix, e.g. NameCol for a name
+// column. For a .go file, the names are
+// pluralized if they are taken by the file, e.g. OrgMembers for the table of
+// the OrgMember struct.
+//
+// sqgen is typically run with go:generate:
+//
+//	//go:generate go run github.com/userhubdev/sq/cmd/sqgen -in schema.sql -pkg db -out tables_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	in := flag.String("in", "", "SQL DDL (.sql) or Go (.go) file to read")
+	out := flag.String("out", "", "Go file to write (default stdout)")
+	pkg := flag.String("pkg", "", "package of the generated code (default: the package of a .go input, or the directory name of -out)")
+	flag.Parse()
+
+	if err := run(*in, *out, *pkg); err != nil {
+		fmt.Fprintln(os.Stderr, "sqgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out, pkg string) error {
+	if len(in) == 0 {
+		return fmt.Errorf("-in is required")
+	}
+
+	src, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+
+	var s *schema
+	switch ext := filepath.Ext(in); ext {
+	case ".sql":
+		s, err = parseDDL(string(src))
+	case ".go":
+		s, err = parseStructs(in, src)
+	default:
+		err = fmt.Errorf("unsupported input file type %q", ext)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(pkg) > 0 {
+		s.pkg = pkg
+	}
+	if len(s.pkg) == 0 {
+		dir, err := filepath.Abs(filepath.Dir(out))
+		if err != nil {
+			return err
+		}
+		s.pkg = strings.ReplaceAll(filepath.Base(dir), "-", "_")
+	}
+
+	code, err := generate(s)
+	if err != nil {
+		return err
+	}
+
+	if len(out) == 0 {
+		_, err = os.Stdout.Write(code)
+		return err
+	}
+	return os.WriteFile(out, code, 0o644)
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/userhubdev/sq"
+)
+
+const testDDL = `
+-- users of an organization
+CREATE TABLE IF NOT EXISTS "users" (
+	id          bigserial PRIMARY KEY,
+	org_id      bigint NOT NULL REFERENCES orgs (id),
+	email       varchar(255) NOT NULL, /* unique per org */
+	name        text,
+	balance     numeric(10, 2) DEFAULT 0,
+	tags        text[] NOT NULL DEFAULT '{}',
+	settings    jsonb,
+	created_at  timestamp with time zone NOT NULL DEFAULT now(),
+	location    point,
+	CONSTRAINT users_email_key UNIQUE (org_id, email)
+);
+
+CREATE INDEX users_org_id ON users (org_id);
+
+CREATE TABLE api_keys (key_hash bytea PRIMARY KEY, user_id bigint NOT NULL, "table" text NOT NULL);
+`
+
+func TestParseDDL(t *testing.T) {
+	s, err := parseDDL(testDDL)
+	require.NoError(t, err)
+	require.Equal(t, []table{
+		{name: "users", goName: "Users", columns: []column{
+			{name: "id", goName: "ID", goType: "int64"},
+			{name: "org_id", goName: "OrgID", goType: "int64"},
+			{name: "email", goName: "Email", goType: "string"},
+			{name: "name", goName: "Name", goType: "*string"},
+			{name: "balance", goName: "Balance", goType: "*string"},
+			{name: "tags", goName: "Tags", goType: "[]string"},
+			{name: "settings", goName: "Settings", goType: "json.RawMessage"},
+			{name: "created_at", goName: "CreatedAt", goType: "time.Time"},
+			{name: "location", goName: "Location", goType: "any"},
+		}},
+		{name: "api_keys", goName: "APIKeys", columns: []column{
+			{name: "key_hash", goName: "KeyHash", goType: "[]byte"},
+			{name: "user_id", goName: "UserID", goType: "int64"},
+			{name: "table", goName: "Table", goType: "string"},
+		}},
+	}, s.tables)
+	require.Equal(t, map[string]string{"encoding/json": "", "time": ""}, s.imports)
+
+	_, err = parseDDL("CREATE INDEX users_org_id ON users (org_id);")
+	require.EqualError(t, err, "no CREATE TABLE statements found")
+}
+
+const testStructs = `package models
+
+import (
+	"database/sql"
+	t "time"
+)
+
+type Timestamps struct {
+	CreatedAt t.Time ` + "`db:\"created_at\"`" + `
+}
+
+// User is a user.
+//
+//sqgen:table users
+type User struct {
+	ID    int64          ` + "`db:\"id,pk\"`" + `
+	Email string         ` + "`db:\"email\"`" + `
+	Name  sql.NullString ` + "`db:\"name,omitempty\"`" + `
+	Temp  string         ` + "`db:\"-\"`" + `
+	Other string
+	Timestamps
+}
+
+type OrgMember struct {
+	OrgID  int64 ` + "`db:\"org_id\"`" + `
+	UserID int64 ` + "`db:\"user_id\"`" + `
+}
+
+type options struct {
+	verbose bool
+}
+`
+
+func TestParseStructs(t *testing.T) {
+	s, err := parseStructs("models.go", []byte(testStructs))
+	require.NoError(t, err)
+	require.Equal(t, "models", s.pkg)
+	require.Equal(t, []table{
+		{name: "users", goName: "Users", columns: []column{
+			{name: "id", goName: "ID", goType: "int64"},
+			{name: "email", goName: "Email", goType: "string"},
+			{name: "name", goName: "Name", goType: "sql.NullString"},
+			{name: "created_at", goName: "CreatedAt", goType: "t.Time"},
+		}},
+		{name: "org_member", goName: "OrgMembers", columns: []column{
+			{name: "org_id", goName: "OrgID", goType: "int64"},
+			{name: "user_id", goName: "UserID", goType: "int64"},
+		}},
+	}, s.tables)
+	require.Equal(t, map[string]string{"database/sql": "", "time": "t"}, s.imports)
+}
+
+func TestGenerateStructsCompiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a package")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+
+	// The generated code is written to the package of the structs, which
+	// must be in this module to import sq.
+	dir, err := os.MkdirTemp(".", "_models")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	in := filepath.Join(dir, "models.go")
+	require.NoError(t, os.WriteFile(in, []byte(testStructs), 0o644))
+	require.NoError(t, run(in, filepath.Join(dir, "tables_gen.go"), ""))
+
+	cmd := exec.Command(goBin, "build", "./"+filepath.Base(dir))
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func TestGenerate(t *testing.T) {
+	s, err := parseDDL(`CREATE TABLE api_keys (
+		id bigint PRIMARY KEY,
+		"table" text NOT NULL,
+		name text NOT NULL,
+		expires_at timestamp
+	);`)
+	require.NoError(t, err)
+	s.pkg = "db"
+
+	code, err := generate(s)
+	require.NoError(t, err)
+	require.Equal(t, `// Code generated by sqgen. DO NOT EDIT.
+
+package db
+
+import (
+	"time"
+
+	"github.com/userhubdev/sq"
+)
+
+// APIKeysTable is the api_keys table.
+type APIKeysTable struct {
+	sq.Table
+	ID        sq.Column[int64]
+	TableCol  sq.Column[string]
+	NameCol   sq.Column[string]
+	ExpiresAt sq.Column[*time.Time]
+}
+
+// APIKeys is the api_keys table.
+var APIKeys = newAPIKeysTable("")
+
+func newAPIKeysTable(alias string) APIKeysTable {
+	tbl := sq.NewTable("api_keys", "id", "table", "name", "expires_at").As(alias)
+	return APIKeysTable{
+		Table:     tbl,
+		ID:        sq.NewColumn[int64](tbl, "id"),
+		TableCol:  sq.NewColumn[string](tbl, "table"),
+		NameCol:   sq.NewColumn[string](tbl, "name"),
+		ExpiresAt: sq.NewColumn[*time.Time](tbl, "expires_at"),
+	}
+}
+
+// As returns the table with an alias, which qualifies its columns.
+func (t APIKeysTable) As(alias string) APIKeysTable {
+	return newAPIKeysTable(alias)
+}
+`, string(code))
+}
+
+func TestReservedFields(t *testing.T) {
+	// the fields of the generated struct must not hide the methods of sq.Table
+	typ := reflect.TypeOf(sq.Table{})
+	for i := 0; i < typ.NumMethod(); i++ {
+		require.True(t, reservedFields[typ.Method(i).Name], typ.Method(i).Name)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "schema.sql")
+	out := filepath.Join(dir, "tables_gen.go")
+	require.NoError(t, os.WriteFile(in, []byte(testDDL), 0o644))
+
+	require.NoError(t, run(in, out, "db"))
+
+	code, err := os.ReadFile(out)
+	require.NoError(t, err)
+	require.Contains(t, string(code), "package db\n")
+	require.Contains(t, string(code), "var Users = newUsersTable(\"\")\n")
+	require.Contains(t, string(code), "Settings  sq.Column[json.RawMessage]\n")
+
+	require.EqualError(t, run(filepath.Join(dir, "schema.txt"), out, ""), "open "+filepath.Join(dir, "schema.txt")+": no such file or directory")
+	require.EqualError(t, run("", out, ""), "-in is required")
+}
+
+func TestNames(t *testing.T) {
+	require.Equal(t, "UserID", goName("user_id"))
+	require.Equal(t, "APIKeyURL", goName("api_key_url"))
+	require.Equal(t, "X2fa", goName("2fa"))
+	require.Equal(t, "user_id", snakeCase("UserID"))
+	require.Equal(t, "api_key", snakeCase("APIKey"))
+	require.Equal(t, "org_member", snakeCase("OrgMember"))
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// parseStructs parses the structs with db tagged fields of a Go file. The
+// generated code is meant to be written to the same package, so column types
+// are copied as is, along with the imports they use.
+func parseStructs(filename string, src []byte) (*schema, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &schema{pkg: f.Name.Name}
+
+	imports := make(map[string]importSpec)
+	for _, spec := range f.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		imp := importSpec{path: path}
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+			imp.name = name
+		}
+		imports[name] = imp
+	}
+
+	// The generated code is in the same package, so its names must not clash
+	// with those declared in the file.
+	declared := make(map[string]bool)
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil {
+				declared[decl.Name.Name] = true
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					declared[spec.Name.Name] = true
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						declared[name.Name] = true
+					}
+				}
+			}
+		}
+	}
+
+	structs := make(map[string]*ast.StructType)
+	embedded := make(map[string]bool)
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			structs[ts.Name.Name] = st
+			for _, field := range st.Fields.List {
+				if len(field.Names) == 0 {
+					embedded[embeddedName(field.Type)] = true
+				}
+			}
+		}
+	}
+
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+
+			doc := ts.Doc
+			if doc == nil && len(gen.Specs) == 1 {
+				doc = gen.Doc
+			}
+
+			name := tableDirective(doc)
+			if len(name) == 0 {
+				if embedded[ts.Name.Name] {
+					// only the columns of the structs embedding it
+					continue
+				}
+				name = snakeCase(ts.Name.Name)
+			}
+
+			t := table{name: name, goName: goName(name)}
+			if declared[t.goName] || declared[t.goName+"Table"] {
+				// e.g. the OrgMember struct of the org_member table
+				t.goName = pluralize(t.goName)
+				if declared[t.goName] || declared[t.goName+"Table"] {
+					return nil, fmt.Errorf("%s: the names of table %s are taken, set another with sqgen:table", ts.Name.Name, name)
+				}
+			}
+			if err := s.addStructColumns(&t, fset, st, structs, imports); err != nil {
+				return nil, fmt.Errorf("%s: %w", ts.Name.Name, err)
+			}
+			if len(t.columns) > 0 {
+				s.tables = append(s.tables, t)
+			}
+		}
+	}
+
+	if len(s.tables) == 0 {
+		return nil, fmt.Errorf("no structs with db tags found")
+	}
+	return s, nil
+}
+
+// importSpec is an import of a Go file.
+type importSpec struct {
+	path string
+	// name is the name the package is imported as, if it is set explicitly.
+	name string
+}
+
+// addStructColumns adds the db tagged fields of st to t, including those of
+// untagged embedded structs declared in the same file.
+func (s *schema) addStructColumns(t *table, fset *token.FileSet, st *ast.StructType, structs map[string]*ast.StructType, imports map[string]importSpec) error {
+	for _, field := range st.Fields.List {
+		var tag string
+		var hasTag bool
+		if field.Tag != nil {
+			value, _ := strconv.Unquote(field.Tag.Value)
+			tag, hasTag = reflect.StructTag(value).Lookup("db")
+		}
+
+		if !hasTag {
+			if len(field.Names) == 0 {
+				if embedded := structs[embeddedName(field.Type)]; embedded != nil {
+					if err := s.addStructColumns(t, fset, embedded, structs, imports); err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" || len(name) == 0 {
+			continue
+		}
+
+		var typ bytes.Buffer
+		if err := printer.Fprint(&typ, fset, field.Type); err != nil {
+			return err
+		}
+
+		ast.Inspect(field.Type, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if pkg, ok := sel.X.(*ast.Ident); ok {
+				if imp, ok := imports[pkg.Name]; ok {
+					s.addImport(imp.path, imp.name)
+				}
+			}
+			return false
+		})
+
+		t.columns = append(t.columns, column{name: name, goName: goName(name), goType: typ.String()})
+	}
+	return nil
+}
+
+// tableDirective returns the table name of a "sqgen:table name" line of a doc
+// comment.
+func tableDirective(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	for _, c := range doc.List {
+		line := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+		if name, ok := strings.CutPrefix(line, "sqgen:table "); ok {
+			return strings.TrimSpace(name)
+		}
+	}
+	return ""
+}
+
+// embeddedName returns the name of the type of an embedded field declared in
+// the same file, or "" otherwise.
+func embeddedName(typ ast.Expr) string {
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	if ident, ok := typ.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// pluralize returns the plural of an English noun, e.g. OrgMember to
+// OrgMembers.
+func pluralize(name string) string {
+	switch {
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "z"),
+		strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	}
+	return name + "s"
+}
@@ -0,0 +1,128 @@
+package sq
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/userhubdev/sq/internal/builder"
+)
+
+// CountQuery returns a query which counts the rows of the query, ignoring its
+// ORDER BY, LIMIT, OFFSET and row locking clauses, e.g. for the total of a
+// paginated list.
+//
+// The result columns are replaced by COUNT(*), unless the query has GROUP BY,
+// HAVING or DISTINCT, in which case it is wrapped in a subquery.
+//
+// Ex:
+//
+//	Select("id", "name").From("users").Where(Eq{"org_id": 1}).OrderBy("name").Limit(20).CountQuery()
+//	// SELECT COUNT(*) FROM users WHERE org_id = ?
+//
+//	Select("org_id").From("users").GroupBy("org_id").CountQuery()
+//	// SELECT COUNT(*) FROM (SELECT org_id FROM users GROUP BY org_id) AS count_query
+func (b SelectBuilder) CountQuery() SelectBuilder {
+	data := builder.GetStruct(b).(selectData)
+
+	wrap := len(data.GroupBys) > 0 || len(data.HavingParts) > 0
+	for _, opt := range data.Options {
+		wrap = wrap || strings.HasPrefix(strings.ToUpper(opt), "DISTINCT")
+	}
+
+	if !wrap {
+		return b.withoutPaging().RemoveColumns().Column("COUNT(*)")
+	}
+
+	inner := b.withoutPaging().RemovePrefixes().RemoveWith()
+	return b.derive().Column("COUNT(*)").FromSelect(inner, "count_query")
+}
+
+// ExistsQuery returns a query which selects whether the query has any rows,
+// ignoring its ORDER BY, LIMIT, OFFSET and row locking clauses.
+//
+// Ex:
+//
+//	Select("id").From("users").Where(Eq{"email": email}).ExistsQuery()
+//	// SELECT EXISTS (SELECT 1 FROM users WHERE email = ?)
+//
+// For dialects without FeatureSelectExists, such as SQL Server, the result is
+// 1 or 0: SELECT CASE WHEN EXISTS (...) THEN 1 ELSE 0 END. Oracle selects it
+// FROM DUAL.
+func (b SelectBuilder) ExistsQuery() SelectBuilder {
+	inner := b.withoutPaging().RemovePrefixes().RemoveWith().RemoveColumns().Column("1")
+	return b.derive().Column(existsColumn{inner})
+}
+
+// CountQuery returns a query which counts the rows of the compound query,
+// ignoring its ORDER BY, LIMIT and OFFSET clauses.
+//
+// Ex:
+//
+//	UnionAll(Select("id").From("users"), Select("id").From("admins")).CountQuery()
+//	// SELECT COUNT(*) FROM (SELECT id FROM users UNION ALL SELECT id FROM admins) AS count_query
+func (b CompoundBuilder) CountQuery() SelectBuilder {
+	data := builder.GetStruct(b).(compoundData)
+
+	inner := b.RemoveOrderBy().RemoveLimit().RemoveOffset()
+
+	count := SelectBuilder(builder.EmptyBuilder).PlaceholderFormat(data.PlaceholderFormat)
+	if data.Dialect != nil {
+		count = builder.Set(count, "Dialect", data.Dialect).(SelectBuilder)
+	}
+	if data.RunWith != nil {
+		count = count.RunWith(data.RunWith)
+	}
+
+	return count.Column("COUNT(*)").FromSelect(inner, "count_query")
+}
+
+// withoutPaging removes the clauses which don't affect which rows match the
+// query.
+func (b SelectBuilder) withoutPaging() SelectBuilder {
+	return b.RemoveOrderBy().RemoveLimit().RemoveOffset().RemoveFor()
+}
+
+// derive returns an empty query with the PlaceholderFormat, Dialect, Runner,
+// prefixes and WITH clause of the query.
+func (b SelectBuilder) derive() SelectBuilder {
+	data := builder.GetStruct(b).(selectData)
+
+	d := SelectBuilder(builder.EmptyBuilder).PlaceholderFormat(data.PlaceholderFormat)
+	if data.Dialect != nil {
+		d = builder.Set(d, "Dialect", data.Dialect).(SelectBuilder)
+	}
+	if data.RunWith != nil {
+		d = d.RunWith(data.RunWith)
+	}
+	if len(data.Prefixes) > 0 {
+		d = builder.Extend(d, "Prefixes", data.Prefixes).(SelectBuilder)
+	}
+	if len(data.WithParts) > 0 {
+		d = builder.Extend(d, "WithParts", data.WithParts).(SelectBuilder)
+	}
+
+	return d
+}
+
+// existsColumn is the result column of ExistsQuery.
+type existsColumn struct {
+	query Sqlizer
+}
+
+func (e existsColumn) ToSql() (string, []any, error) {
+	return e.toSqlContext(renderContext{})
+}
+
+func (e existsColumn) toSqlContext(rc renderContext) (string, []any, error) {
+	sql, args, err := nestedToSql(rc, e.query)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// like pagination, a placeholder format implies its dialect
+	if d := rc.paginationDialect(); d != nil && !d.Supports(FeatureSelectExists) {
+		// EXISTS is a predicate rather than a boolean expression
+		return fmt.Sprintf("CASE WHEN EXISTS (%s) THEN 1 ELSE 0 END", sql), args, nil
+	}
+	return fmt.Sprintf("EXISTS (%s)", sql), args, nil
+}
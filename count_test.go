@@ -0,0 +1,124 @@
+package sq
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectBuilder_CountQuery(t *testing.T) {
+	base := Select("id", "name").From("users u").
+		Join("orgs o ON o.id = u.org_id").
+		Where(Eq{"o.plan": "pro"})
+
+	tests := []struct {
+		name string
+		b    SelectBuilder
+		sql  string
+	}{
+		{
+			name: "plain",
+			b:    base.OrderBy("name").Limit(20).Offset(40).For(LockUpdate).CountQuery(),
+			sql:  "SELECT COUNT(*) FROM users u JOIN orgs o ON o.id = u.org_id WHERE o.plan = $1",
+		},
+		{
+			name: "group by",
+			b:    base.GroupBy("o.id").Having("COUNT(*) > ?", 2).OrderBy("o.id").CountQuery(),
+			sql: "SELECT COUNT(*) FROM (SELECT id, name FROM users u JOIN orgs o ON o.id = u.org_id " +
+				"WHERE o.plan = $1 GROUP BY o.id HAVING COUNT(*) > $2) AS count_query",
+		},
+		{
+			name: "distinct",
+			b:    base.Distinct().Limit(5).CountQuery(),
+			sql: "SELECT COUNT(*) FROM (SELECT DISTINCT id, name FROM users u JOIN orgs o ON o.id = u.org_id " +
+				"WHERE o.plan = $1) AS count_query",
+		},
+		{
+			name: "with",
+			b:    base.With("active", Select("id").From("users").Where("active = ?", true)).Distinct().CountQuery(),
+			sql: "WITH active AS ( SELECT id FROM users WHERE active = $1) " +
+				"SELECT COUNT(*) FROM (SELECT DISTINCT id, name FROM users u JOIN orgs o ON o.id = u.org_id " +
+				"WHERE o.plan = $2) AS count_query",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sql, _, err := test.b.PlaceholderFormat(Dollar).ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.sql, sql)
+		})
+	}
+}
+
+func TestSelectBuilder_CountQueryOracle(t *testing.T) {
+	// Oracle doesn't accept AS before a table alias.
+	sql, _, err := Select("a").Distinct().From("t").Dialect(Oracle).CountQuery().ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT COUNT(*) FROM (SELECT DISTINCT a FROM t) count_query", sql)
+
+	sql, _, err = Select("a").From("t").Union(Select("a").From("u")).Dialect(Oracle).CountQuery().ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT COUNT(*) FROM (SELECT a FROM t UNION SELECT a FROM u) count_query", sql)
+
+	sql, _, err = Select("a").From("t").JoinSelect(Select("b").From("u"), "x", Expr("x.b = t.a")).Dialect(Oracle).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT a FROM t JOIN (SELECT b FROM u) x ON x.b = t.a", sql)
+}
+
+func TestSelectBuilder_CountQueryRunner(t *testing.T) {
+	db, fake := newFakeDB([]string{"count"}, []driver.Value{int64(3)})
+
+	var count int
+	err := StatementBuilder.Dialect(Postgres).RunWith(db).
+		Select("id").From("users").Where(Eq{"org_id": 1}).Limit(10).
+		CountQuery().
+		QueryRowContext(context.Background()).
+		Scan(&count)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+	require.Equal(t, []string{"SELECT COUNT(*) FROM users WHERE org_id = $1"}, fake.queries)
+}
+
+func TestCompoundBuilder_CountQuery(t *testing.T) {
+	sql, args, err := Select("id").From("users").Where("org_id = ?", 1).
+		UnionAll(Select("id").From("admins").Where("org_id = ?", 2)).
+		OrderBy("id").
+		Limit(10).
+		PlaceholderFormat(Dollar).
+		CountQuery().
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT COUNT(*) FROM (SELECT id FROM users WHERE org_id = $1 "+
+		"UNION ALL SELECT id FROM admins WHERE org_id = $2) AS count_query", sql)
+	require.Equal(t, []any{1, 2}, args)
+}
+
+func TestSelectBuilder_ExistsQuery(t *testing.T) {
+	b := Select("id", "name").From("users").Where(Eq{"email": "a@example.com"}).OrderBy("id").Limit(1)
+
+	sql, args, err := b.ExistsQuery().ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT EXISTS (SELECT 1 FROM users WHERE email = ?)", sql)
+	require.Equal(t, []any{"a@example.com"}, args)
+
+	sql, _, err = b.Dialect(Postgres).ExistsQuery().ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT EXISTS (SELECT 1 FROM users WHERE email = $1)", sql)
+
+	sql, _, err = b.Dialect(SQLServer).ExistsQuery().ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT CASE WHEN EXISTS (SELECT 1 FROM users WHERE email = @p1) THEN 1 ELSE 0 END", sql)
+
+	// the placeholder format implies SQL Server
+	sql, _, err = b.PlaceholderFormat(AtP).ExistsQuery().ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT CASE WHEN EXISTS (SELECT 1 FROM users WHERE email = @p1) THEN 1 ELSE 0 END", sql)
+
+	sql, _, err = b.Dialect(Oracle).ExistsQuery().ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT CASE WHEN EXISTS (SELECT 1 FROM users WHERE email = :1) THEN 1 ELSE 0 END FROM DUAL", sql)
+
+}
@@ -1,6 +1,8 @@
 package sq
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"strings"
 
@@ -9,16 +11,57 @@ import (
 
 type deleteData struct {
 	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
+	RunWith           Runner
 	Prefixes          []Sqlizer
+	WithParts         []withPart
 	From              string
+	Usings            []string
+	Joins             []Sqlizer
 	WhereParts        []Sqlizer
 	OrderBys          []string
 	Limit             string
 	Offset            string
 	Suffixes          []Sqlizer
+	Returning         []returningPart
+}
+
+func (d *deleteData) ExecContext(ctx context.Context) (sql.Result, error) {
+	if d.RunWith == nil {
+		return nil, ErrRunnerNotSet
+	}
+	return ExecContextWith(ctx, d.RunWith, d)
+}
+
+func (d *deleteData) QueryContext(ctx context.Context) (*sql.Rows, error) {
+	if d.RunWith == nil {
+		return nil, ErrRunnerNotSet
+	}
+	return QueryContextWith(ctx, d.RunWith, d)
+}
+
+func (d *deleteData) QueryRowContext(ctx context.Context) RowScanner {
+	if d.RunWith == nil {
+		return &Row{err: ErrRunnerNotSet}
+	}
+	return QueryRowContextWith(ctx, d.RunWith, d)
 }
 
 func (d *deleteData) ToSql() (sqlStr string, args []any, err error) {
+	sqlStr, args, err = d.ToSqlRaw()
+	if err != nil {
+		return
+	}
+
+	sqlStr, args, err = replacePlaceholders(d.PlaceholderFormat, sqlStr, args)
+	return
+}
+
+func (d *deleteData) ToSqlRaw() (sqlStr string, args []any, err error) {
+	return d.toSqlRaw(renderContext{dialect: d.Dialect, format: d.PlaceholderFormat})
+}
+
+func (d *deleteData) toSqlRaw(rc renderContext) (sqlStr string, args []any, err error) {
 	if len(d.From) == 0 {
 		err = fmt.Errorf("delete statements must specify a From table")
 		return
@@ -27,7 +70,7 @@ func (d *deleteData) ToSql() (sqlStr string, args []any, err error) {
 	sql := &strings.Builder{}
 
 	if len(d.Prefixes) > 0 {
-		args, err = appendToSql(d.Prefixes, sql, " ", args)
+		args, err = appendToSql(rc, d.Prefixes, sql, " ", args)
 		if err != nil {
 			return
 		}
@@ -35,41 +78,104 @@ func (d *deleteData) ToSql() (sqlStr string, args []any, err error) {
 		sql.WriteString(" ")
 	}
 
-	sql.WriteString("DELETE FROM ")
+	if len(d.WithParts) > 0 {
+		args, err = appendWithToSql(rc, d.WithParts, sql, args)
+		if err != nil {
+			return
+		}
+	}
+
+	top, limit, err := rc.updateLimit(d.Limit, d.Offset, len(d.OrderBys) > 0)
+	if err != nil {
+		return
+	}
+	if len(d.Joins) > 0 && (len(d.OrderBys) > 0 || len(limit) > 0) {
+		// MySQL doesn't allow them in multiple-table statements.
+		err = fmt.Errorf("ORDER BY and LIMIT cannot be used with joins in DELETE statements")
+		return
+	}
+
+	returning, returningArgs, err := returningToSql(rc, d.Returning, "DELETED")
+	if err != nil {
+		return
+	}
+	output := rc.useOutput()
+
+	// Joins follow the USING clause if there is one. Otherwise the alias of
+	// the table is deleted from, and the table is joined in the FROM clause.
+	joinFrom := len(d.Joins) > 0 && len(d.Usings) == 0
+	if joinFrom {
+		if err = rc.require(FeatureDeleteJoin); err != nil {
+			return
+		}
+	}
+
+	sql.WriteString("DELETE ")
+	sql.WriteString(top)
+	if joinFrom {
+		sql.WriteString(tableAlias(d.From))
+		sql.WriteString(" ")
+		if output && len(returning) > 0 {
+			sql.WriteString(returning)
+			sql.WriteString(" ")
+			args = append(args, returningArgs...)
+		}
+	}
+	sql.WriteString("FROM ")
 	sql.WriteString(d.From)
 
+	if output && len(returning) > 0 && !joinFrom {
+		sql.WriteString(" ")
+		sql.WriteString(returning)
+		args = append(args, returningArgs...)
+	}
+
+	if len(d.Usings) > 0 {
+		if err = rc.require(FeatureDeleteUsing); err != nil {
+			return
+		}
+		sql.WriteString(" USING ")
+		sql.WriteString(strings.Join(d.Usings, ", "))
+	}
+
+	if len(d.Joins) > 0 {
+		sql.WriteString(" ")
+		args, err = appendToSql(rc, d.Joins, sql, " ", args)
+		if err != nil {
+			return
+		}
+	}
+
 	if len(d.WhereParts) > 0 {
 		sql.WriteString(" WHERE ")
-		args, err = appendToSql(d.WhereParts, sql, " AND ", args)
+		args, err = appendToSql(rc, d.WhereParts, sql, " AND ", args)
 		if err != nil {
 			return
 		}
 	}
 
+	if !output && len(returning) > 0 {
+		sql.WriteString(" ")
+		sql.WriteString(returning)
+		args = append(args, returningArgs...)
+	}
+
 	if len(d.OrderBys) > 0 {
 		sql.WriteString(" ORDER BY ")
 		sql.WriteString(strings.Join(d.OrderBys, ", "))
 	}
 
-	if len(d.Limit) > 0 {
-		sql.WriteString(" LIMIT ")
-		sql.WriteString(d.Limit)
-	}
-
-	if len(d.Offset) > 0 {
-		sql.WriteString(" OFFSET ")
-		sql.WriteString(d.Offset)
-	}
+	sql.WriteString(limit)
 
 	if len(d.Suffixes) > 0 {
 		sql.WriteString(" ")
-		args, err = appendToSql(d.Suffixes, sql, " ", args)
+		args, err = appendToSql(rc, d.Suffixes, sql, " ", args)
 		if err != nil {
 			return
 		}
 	}
 
-	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sql.String())
+	sqlStr = sql.String()
 	return
 }
 
@@ -90,6 +196,45 @@ func (b DeleteBuilder) PlaceholderFormat(f PlaceholderFormat) DeleteBuilder {
 	return builder.Set(b, "PlaceholderFormat", f).(DeleteBuilder)
 }
 
+// Dialect sets the Dialect (e.g. Postgres or MySQL) for the query, and sets the
+// PlaceholderFormat to the dialect's default. A nil Dialect clears it, keeping
+// the PlaceholderFormat.
+func (b DeleteBuilder) Dialect(d Dialect) DeleteBuilder {
+	if d == nil {
+		return builder.Delete(b, "Dialect").(DeleteBuilder)
+	}
+	b = builder.Set(b, "Dialect", d).(DeleteBuilder)
+	return b.PlaceholderFormat(d.PlaceholderFormat())
+}
+
+// Runner methods
+
+// RunWith sets a Runner (like database/sql.DB) to be used with e.g.
+// ExecContext.
+func (b DeleteBuilder) RunWith(runner Runner) DeleteBuilder {
+	return builder.Set(b, "RunWith", runner).(DeleteBuilder)
+}
+
+// ExecContext builds and ExecContexts the query with the Runner set by RunWith.
+func (b DeleteBuilder) ExecContext(ctx context.Context) (sql.Result, error) {
+	data := builder.GetStruct(b).(deleteData)
+	return data.ExecContext(ctx)
+}
+
+// QueryContext builds and QueryContexts the query with the Runner set by
+// RunWith.
+func (b DeleteBuilder) QueryContext(ctx context.Context) (*sql.Rows, error) {
+	data := builder.GetStruct(b).(deleteData)
+	return data.QueryContext(ctx)
+}
+
+// QueryRowContext builds and QueryRowContexts the query with the Runner set by
+// RunWith.
+func (b DeleteBuilder) QueryRowContext(ctx context.Context) RowScanner {
+	data := builder.GetStruct(b).(deleteData)
+	return data.QueryRowContext(ctx)
+}
+
 // SQL methods
 
 // ToSql builds the query into a SQL string and bound args.
@@ -98,6 +243,43 @@ func (b DeleteBuilder) ToSql() (string, []any, error) {
 	return data.ToSql()
 }
 
+// ToSqlRaw builds the query into a SQL string and bound args without
+// replacing the placeholders, for use in nested queries.
+func (b DeleteBuilder) ToSqlRaw() (string, []any, error) {
+	data := builder.GetStruct(b).(deleteData)
+	return data.ToSqlRaw()
+}
+
+// toSqlContext builds a nested query, inheriting the Dialect of the enclosing
+// statement if none is set.
+func (b DeleteBuilder) toSqlContext(rc renderContext) (string, []any, error) {
+	data := builder.GetStruct(b).(deleteData)
+	if data.Dialect != nil {
+		rc.dialect = data.Dialect
+	}
+	return data.toSqlRaw(rc)
+}
+
+// With adds a common table expression to the WITH clause of the query.
+//
+// See WithBuilder.As.
+func (b DeleteBuilder) With(alias string, sql Sqlizer, opts ...CTEOption) DeleteBuilder {
+	return builder.Append(b, "WithParts", newWithPart(alias, sql, opts)).(DeleteBuilder)
+}
+
+// WithRecursive adds a recursive common table expression to the WITH clause
+// of the query.
+//
+// See WithBuilder.Recursive.
+func (b DeleteBuilder) WithRecursive(alias string, anchor, recursive SelectBuilder, opts ...CTEOption) DeleteBuilder {
+	return builder.Append(b, "WithParts", newRecursiveWithPart(alias, anchor, recursive, opts)).(DeleteBuilder)
+}
+
+// RemoveWith removes the WITH clause.
+func (b DeleteBuilder) RemoveWith() DeleteBuilder {
+	return builder.Delete(b, "WithParts").(DeleteBuilder)
+}
+
 // Prefix adds an expression to the beginning of the query
 func (b DeleteBuilder) Prefix(sql string, args ...any) DeleteBuilder {
 	return b.PrefixExpr(Expr(sql, args...))
@@ -113,6 +295,97 @@ func (b DeleteBuilder) From(from string) DeleteBuilder {
 	return builder.Set(b, "From", from).(DeleteBuilder)
 }
 
+// Using adds tables to the USING clause of the query, which PostgreSQL uses
+// to join other tables to the one being deleted from.
+//
+// Ex:
+//
+//	Delete("orders o").Using("users u").Where("o.user_id = u.id AND u.banned")
+//	// DELETE FROM orders o USING users u WHERE o.user_id = u.id AND u.banned
+func (b DeleteBuilder) Using(tables ...string) DeleteBuilder {
+	return builder.Extend(b, "Usings", tables).(DeleteBuilder)
+}
+
+// JoinClause adds a join clause to the query.
+//
+// If Using is set, joins are rendered after the USING clause, as PostgreSQL
+// requires (the target table is then joined in the WHERE clause). Otherwise
+// they join the target table directly, as "DELETE a FROM a JOIN b ON ..." for
+// MySQL and SQL Server.
+func (b DeleteBuilder) JoinClause(pred any, args ...any) DeleteBuilder {
+	return builder.Append(b, "Joins", newPart(pred, args...)).(DeleteBuilder)
+}
+
+// RemoveJoins removes JOIN clauses.
+func (b DeleteBuilder) RemoveJoins() DeleteBuilder {
+	return builder.Delete(b, "Joins").(DeleteBuilder)
+}
+
+// Join adds a JOIN clause to the query.
+func (b DeleteBuilder) Join(join string, rest ...any) DeleteBuilder {
+	return b.JoinClause("JOIN "+join, rest...)
+}
+
+// LeftJoin adds a LEFT JOIN clause to the query.
+func (b DeleteBuilder) LeftJoin(join string, rest ...any) DeleteBuilder {
+	return b.JoinClause("LEFT JOIN "+join, rest...)
+}
+
+// RightJoin adds a RIGHT JOIN clause to the query.
+func (b DeleteBuilder) RightJoin(join string, rest ...any) DeleteBuilder {
+	return b.JoinClause("RIGHT JOIN "+join, rest...)
+}
+
+// InnerJoin adds a INNER JOIN clause to the query.
+func (b DeleteBuilder) InnerJoin(join string, rest ...any) DeleteBuilder {
+	return b.JoinClause("INNER JOIN "+join, rest...)
+}
+
+// CrossJoin adds a CROSS JOIN clause to the query.
+func (b DeleteBuilder) CrossJoin(join string, rest ...any) DeleteBuilder {
+	return b.JoinClause("CROSS JOIN "+join, rest...)
+}
+
+// FullJoin adds a FULL JOIN clause to the query.
+func (b DeleteBuilder) FullJoin(join string, rest ...any) DeleteBuilder {
+	return b.JoinClause("FULL JOIN "+join, rest...)
+}
+
+// joinOn adds a join on clause to the query,
+func (b DeleteBuilder) joinOn(prefix string, join any, on Sqlizer) DeleteBuilder {
+	return b.JoinClause(ConcatExpr(prefix, " ", join, " ON ", on))
+}
+
+// JoinOn adds a JOIN ON clause to the query.
+func (b DeleteBuilder) JoinOn(join any, on Sqlizer) DeleteBuilder {
+	return b.joinOn("JOIN", join, on)
+}
+
+// LeftJoinOn adds a LEFT JOIN ON clause to the query.
+func (b DeleteBuilder) LeftJoinOn(join any, on Sqlizer) DeleteBuilder {
+	return b.joinOn("LEFT JOIN", join, on)
+}
+
+// RightJoinOn adds a RIGHT JOIN ON clause to the query.
+func (b DeleteBuilder) RightJoinOn(join any, on Sqlizer) DeleteBuilder {
+	return b.joinOn("RIGHT JOIN", join, on)
+}
+
+// InnerJoinOn adds a INNER JOIN ON clause to the query.
+func (b DeleteBuilder) InnerJoinOn(join any, on Sqlizer) DeleteBuilder {
+	return b.joinOn("INNER JOIN", join, on)
+}
+
+// CrossJoinOn adds a CROSS JOIN ON clause to the query.
+func (b DeleteBuilder) CrossJoinOn(join any, on Sqlizer) DeleteBuilder {
+	return b.joinOn("CROSS JOIN", join, on)
+}
+
+// FullJoinOn adds a FULL JOIN ON clause to the query.
+func (b DeleteBuilder) FullJoinOn(join any, on Sqlizer) DeleteBuilder {
+	return b.joinOn("FULL JOIN", join, on)
+}
+
 // Where adds WHERE expressions to the query.
 //
 // See SelectBuilder.Where for more information.
@@ -126,6 +399,9 @@ func (b DeleteBuilder) OrderBy(orderBys ...string) DeleteBuilder {
 }
 
 // Limit sets a LIMIT clause on the query.
+//
+// Depending on the Dialect (or, if none is set, the PlaceholderFormat), the
+// limit is rendered as LIMIT n or TOP (n).
 func (b DeleteBuilder) Limit(limit uint64) DeleteBuilder {
 	return builder.Set(b, "Limit", fmt.Sprintf("%d", limit)).(DeleteBuilder)
 }
@@ -144,3 +420,15 @@ func (b DeleteBuilder) Suffix(sql string, args ...any) DeleteBuilder {
 func (b DeleteBuilder) SuffixExpr(expr Sqlizer) DeleteBuilder {
 	return builder.Append(b, "Suffixes", expr).(DeleteBuilder)
 }
+
+// Returning adds columns to the RETURNING clause of the query, or for SQL
+// Server the OUTPUT clause, where they are qualified with DELETED.
+func (b DeleteBuilder) Returning(columns ...string) DeleteBuilder {
+	return builder.Extend(b, "Returning", returningColumns(columns)).(DeleteBuilder)
+}
+
+// ReturningExpr adds an expression to the RETURNING (or OUTPUT) clause of the
+// query.
+func (b DeleteBuilder) ReturningExpr(expr Sqlizer) DeleteBuilder {
+	return builder.Append(b, "Returning", returningPart{expr: expr}).(DeleteBuilder)
+}
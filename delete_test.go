@@ -43,3 +43,45 @@ func TestDeleteBuilderPlaceholders(t *testing.T) {
 	sql, _, _ = b.PlaceholderFormat(Dollar).ToSql()
 	require.Equal(t, "DELETE FROM test WHERE x = $1 AND y = $2", sql)
 }
+
+func TestDeleteBuilderUsing(t *testing.T) {
+	sql, args, err := Delete("orders o").
+		Using("users u", "orgs g").
+		Join("plans p ON p.id = g.plan_id").
+		Where("o.user_id = u.id AND u.org_id = g.id AND p.free = ?", true).
+		Returning("o.id").
+		Dialect(Postgres).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t,
+		"DELETE FROM orders o USING users u, orgs g JOIN plans p ON p.id = g.plan_id "+
+			"WHERE o.user_id = u.id AND u.org_id = g.id AND p.free = $1 RETURNING o.id",
+		sql)
+	require.Equal(t, []any{true}, args)
+
+	_, _, err = Delete("orders").Using("users").Dialect(MySQL).ToSql()
+	require.EqualError(t, err, "DELETE ... USING is not supported by MySQL")
+}
+
+func TestDeleteBuilderJoins(t *testing.T) {
+	b := Delete("orders o").
+		InnerJoinOn("users u", Expr("u.id = o.user_id")).
+		Where(Eq{"u.banned": true})
+
+	sql, args, err := b.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "DELETE o FROM orders o INNER JOIN users u ON u.id = o.user_id WHERE u.banned = ?", sql)
+	require.Equal(t, []any{true}, args)
+
+	sql, _, err = b.Limit(10).Returning("id").Dialect(SQLServer).ToSql()
+	require.NoError(t, err)
+	require.Equal(t,
+		"DELETE TOP (10) o OUTPUT DELETED.id FROM orders o INNER JOIN users u ON u.id = o.user_id WHERE u.banned = @p1",
+		sql)
+
+	_, _, err = b.Dialect(SQLite).ToSql()
+	require.EqualError(t, err, "DELETE ... JOIN is not supported by SQLite")
+
+	_, _, err = b.OrderBy("o.id").Limit(10).Dialect(MySQL).ToSql()
+	require.EqualError(t, err, "ORDER BY and LIMIT cannot be used with joins in DELETE statements")
+}
@@ -0,0 +1,675 @@
+package sq
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Feature is a SQL construct which is not supported by every Dialect.
+type Feature int
+
+const (
+	// FeatureILike is the ILIKE and NOT ILIKE operators.
+	FeatureILike Feature = iota
+
+	// FeatureLimitOffset is the LIMIT and OFFSET clauses of SELECT.
+	FeatureLimitOffset
+
+	// FeatureOffsetFetch is the OFFSET ... ROWS FETCH NEXT ... ROWS ONLY
+	// clause of SELECT.
+	FeatureOffsetFetch
+
+	// FeatureTop is the TOP clause of SELECT, UPDATE and DELETE.
+	FeatureTop
+
+	// FeatureUpdateFrom is the FROM clause of UPDATE.
+	FeatureUpdateFrom
+
+	// FeatureUpdateLimit is the LIMIT clause of UPDATE and DELETE.
+	FeatureUpdateLimit
+
+	// FeatureUpdateOffset is the OFFSET clause of UPDATE and DELETE.
+	FeatureUpdateOffset
+
+	// FeatureJSONB is the PostgreSQL JSONB operators.
+	FeatureJSONB
+
+	// FeatureOnConflict is the ON CONFLICT clause of INSERT.
+	FeatureOnConflict
+
+	// FeatureOnConstraint is the ON CONFLICT ON CONSTRAINT clause of INSERT.
+	FeatureOnConstraint
+
+	// FeatureOnDuplicateKey is the ON DUPLICATE KEY UPDATE clause of INSERT.
+	FeatureOnDuplicateKey
+
+	// FeatureReturning is the RETURNING clause of INSERT, UPDATE and DELETE.
+	FeatureReturning
+
+	// FeatureOutput is the SQL Server OUTPUT clause of INSERT, UPDATE and
+	// DELETE, which is used in place of RETURNING.
+	FeatureOutput
+
+	// FeatureMerge is the MERGE statement.
+	FeatureMerge
+
+	// FeatureMergeBySource is the WHEN NOT MATCHED BY SOURCE clause of MERGE.
+	FeatureMergeBySource
+
+	// FeatureUpdateJoin is joins after the table of UPDATE, as in
+	// "UPDATE a JOIN b ON ... SET ...".
+	FeatureUpdateJoin
+
+	// FeatureUpdateFromJoin is joins to the target table in the FROM clause
+	// of UPDATE, as in "UPDATE a SET ... FROM a JOIN b ON ...".
+	FeatureUpdateFromJoin
+
+	// FeatureDeleteJoin is joins to the target table of DELETE, as in
+	// "DELETE a FROM a JOIN b ON ...".
+	FeatureDeleteJoin
+
+	// FeatureDeleteUsing is the USING clause of DELETE.
+	FeatureDeleteUsing
+
+	// FeatureFilter is the FILTER (WHERE ...) clause of aggregates.
+	FeatureFilter
+
+	// FeatureFrameGroups is GROUPS window frames.
+	FeatureFrameGroups
+
+	// FeatureFrameExclude is the EXCLUDE option of window frames.
+	FeatureFrameExclude
+
+	// FeatureLockFor is the FOR UPDATE clause of SELECT.
+	FeatureLockFor
+
+	// FeatureLockShare is the FOR SHARE clause of SELECT.
+	FeatureLockShare
+
+	// FeatureLockKeyStrength is the PostgreSQL FOR NO KEY UPDATE and FOR KEY
+	// SHARE clauses of SELECT.
+	FeatureLockKeyStrength
+
+	// FeatureLockHints is the SQL Server table hints used in place of FOR
+	// UPDATE, e.g. WITH (UPDLOCK, READPAST).
+	FeatureLockHints
+
+	// FeatureRecursiveKeyword is the RECURSIVE keyword of WITH clauses, which
+	// some databases require and others reject.
+	FeatureRecursiveKeyword
+
+	// FeatureCTEMaterialized is the MATERIALIZED and NOT MATERIALIZED options
+	// of common table expressions.
+	FeatureCTEMaterialized
+
+	// FeatureCTESearchCycle is the SEARCH and CYCLE clauses of recursive
+	// common table expressions.
+	FeatureCTESearchCycle
+
+	// FeatureDistinctFrom is the IS DISTINCT FROM and IS NOT DISTINCT FROM
+	// operators.
+	FeatureDistinctFrom
+
+	// FeatureNullSafeEqual is the MySQL <=> operator, which is used in place
+	// of IS NOT DISTINCT FROM.
+	FeatureNullSafeEqual
+
+	// FeatureRowValues is row value comparisons, as in "(a, b) > (?, ?)".
+	FeatureRowValues
+
+	// FeatureAnyAll is the ANY and ALL subquery comparisons, as in
+	// "price > ALL (SELECT ...)".
+	FeatureAnyAll
+
+	// FeatureArrayParams is comparisons to an array parameter, as in
+	// "id = ANY(?)". See WithArrayParams.
+	FeatureArrayParams
+
+	// FeatureBackslashEscapes is backslash escapes in string literals, as in
+	// 'it\'s'.
+	FeatureBackslashEscapes
+
+	// FeatureTableAliasAs is the AS keyword before a table alias, as in
+	// "USING staged AS s" or "FROM (SELECT ...) AS q".
+	FeatureTableAliasAs
+
+	// FeatureCompoundParens is parenthesized queries in compound queries, as
+	// in "(SELECT ... UNION SELECT ...) EXCEPT SELECT ...".
+	FeatureCompoundParens
+
+	// FeatureSelectNoFrom is a SELECT without a FROM clause, as in
+	// "SELECT 1". Without it, queries without From select FROM DUAL.
+	FeatureSelectNoFrom
+
+	// FeatureMergeTerminator is the semicolon which must terminate a MERGE
+	// statement.
+	FeatureMergeTerminator
+
+	// FeatureMultiRowValues is inserting several rows with one VALUES clause,
+	// as in "VALUES (?,?),(?,?)".
+	FeatureMultiRowValues
+
+	// FeatureMergeWhenAnd is the conditions and multiple WHEN MATCHED clauses
+	// of MERGE, as in "WHEN MATCHED AND s.deleted THEN DELETE". Without it,
+	// MERGE has Oracle's form, see MergeBuilder.WhenMatched.
+	FeatureMergeWhenAnd
+
+	// FeatureSelectExists is EXISTS as a boolean expression in the select
+	// list, as in "SELECT EXISTS (...)". Without it, ExistsQuery selects
+	// CASE WHEN EXISTS (...) THEN 1 ELSE 0 END.
+	FeatureSelectExists
+
+	numFeatures
+)
+
+var featureNames = [...]string{
+	FeatureILike:            "ILIKE",
+	FeatureLimitOffset:      "LIMIT/OFFSET",
+	FeatureOffsetFetch:      "OFFSET/FETCH",
+	FeatureTop:              "TOP",
+	FeatureUpdateFrom:       "UPDATE ... FROM",
+	FeatureUpdateLimit:      "LIMIT on UPDATE/DELETE",
+	FeatureUpdateOffset:     "OFFSET on UPDATE/DELETE",
+	FeatureJSONB:            "JSONB",
+	FeatureOnConflict:       "ON CONFLICT",
+	FeatureOnConstraint:     "ON CONFLICT ON CONSTRAINT",
+	FeatureOnDuplicateKey:   "ON DUPLICATE KEY UPDATE",
+	FeatureReturning:        "RETURNING",
+	FeatureOutput:           "OUTPUT",
+	FeatureMerge:            "MERGE",
+	FeatureMergeBySource:    "WHEN NOT MATCHED BY SOURCE",
+	FeatureUpdateJoin:       "UPDATE ... JOIN",
+	FeatureUpdateFromJoin:   "UPDATE ... FROM ... JOIN",
+	FeatureDeleteJoin:       "DELETE ... JOIN",
+	FeatureDeleteUsing:      "DELETE ... USING",
+	FeatureFilter:           "FILTER",
+	FeatureFrameGroups:      "GROUPS",
+	FeatureFrameExclude:     "EXCLUDE",
+	FeatureLockFor:          "FOR UPDATE",
+	FeatureLockShare:        "FOR SHARE",
+	FeatureLockKeyStrength:  "FOR NO KEY UPDATE/FOR KEY SHARE",
+	FeatureLockHints:        "table lock hints",
+	FeatureRecursiveKeyword: "WITH RECURSIVE",
+	FeatureCTEMaterialized:  "MATERIALIZED",
+	FeatureCTESearchCycle:   "SEARCH/CYCLE",
+	FeatureDistinctFrom:     "IS DISTINCT FROM",
+	FeatureNullSafeEqual:    "<=>",
+	FeatureRowValues:        "row value comparisons",
+	FeatureAnyAll:           "ANY/ALL",
+	FeatureArrayParams:      "array parameters",
+	FeatureBackslashEscapes: "backslash escapes",
+	FeatureTableAliasAs:     "AS before table aliases",
+	FeatureCompoundParens:   "parenthesized compound query parts",
+	FeatureSelectNoFrom:     "SELECT without FROM",
+	FeatureMergeTerminator:  "MERGE terminator",
+	FeatureMultiRowValues:   "multi-row VALUES",
+	FeatureMergeWhenAnd:     "WHEN ... AND in MERGE",
+	FeatureSelectExists:     "EXISTS in the select list",
+}
+
+// String returns the SQL construct the feature represents.
+func (f Feature) String() string {
+	if f >= 0 && f < numFeatures {
+		return featureNames[f]
+	}
+	return fmt.Sprintf("Feature(%d)", int(f))
+}
+
+// Dialect is the interface that describes the SQL flavour of a database.
+//
+// Builders consult their Dialect to render constructs which differ between
+// databases, and return an error for constructs the Dialect cannot express.
+// Builders without a Dialect render the same SQL as they always have.
+type Dialect interface {
+	// Name returns the name of the database, e.g. "PostgreSQL".
+	Name() string
+
+	// PlaceholderFormat returns the PlaceholderFormat used by the database's
+	// drivers.
+	PlaceholderFormat() PlaceholderFormat
+
+	// QuoteIdent quotes a single identifier, e.g. a table or column name.
+	QuoteIdent(ident string) string
+
+	// BoolLiteral returns an expression which is always true or false.
+	BoolLiteral(v bool) string
+
+	// Supports reports whether the database supports the given feature.
+	Supports(f Feature) bool
+}
+
+var (
+	// Postgres is the Dialect for PostgreSQL.
+	Postgres Dialect = &dialect{
+		name:      "PostgreSQL",
+		format:    Dollar,
+		quote:     [2]string{`"`, `"`},
+		boolTrue:  "TRUE",
+		boolFalse: "FALSE",
+		maxParams: 65535,
+		features: features(
+			FeatureILike,
+			FeatureLimitOffset,
+			FeatureOffsetFetch,
+			FeatureUpdateFrom,
+			FeatureJSONB,
+			FeatureOnConflict,
+			FeatureOnConstraint,
+			FeatureReturning,
+			FeatureMerge,
+			FeatureMergeBySource,
+			FeatureDeleteUsing,
+			FeatureFilter,
+			FeatureFrameGroups,
+			FeatureFrameExclude,
+			FeatureLockFor,
+			FeatureLockShare,
+			FeatureLockKeyStrength,
+			FeatureRecursiveKeyword,
+			FeatureCTEMaterialized,
+			FeatureCTESearchCycle,
+			FeatureDistinctFrom,
+			FeatureRowValues,
+			FeatureAnyAll,
+			FeatureArrayParams,
+			FeatureTableAliasAs,
+			FeatureCompoundParens,
+			FeatureSelectNoFrom,
+			FeatureMultiRowValues,
+			FeatureMergeWhenAnd,
+			FeatureSelectExists,
+		),
+	}
+
+	// MySQL is the Dialect for MySQL and MariaDB.
+	MySQL Dialect = &dialect{
+		name:      "MySQL",
+		format:    Question,
+		quote:     [2]string{"`", "`"},
+		boolTrue:  "TRUE",
+		boolFalse: "FALSE",
+		maxParams: 65535,
+		features: features(
+			FeatureLimitOffset,
+			FeatureUpdateLimit,
+			FeatureOnDuplicateKey,
+			FeatureUpdateJoin,
+			FeatureDeleteJoin,
+			FeatureLockFor,
+			FeatureLockShare,
+			FeatureRecursiveKeyword,
+			FeatureNullSafeEqual,
+			FeatureRowValues,
+			FeatureAnyAll,
+			FeatureBackslashEscapes,
+			FeatureTableAliasAs,
+			FeatureCompoundParens,
+			FeatureSelectNoFrom,
+			FeatureMultiRowValues,
+			FeatureSelectExists,
+		),
+	}
+
+	// SQLite is the Dialect for SQLite.
+	SQLite Dialect = &dialect{
+		name:      "SQLite",
+		format:    Question,
+		quote:     [2]string{`"`, `"`},
+		boolTrue:  "TRUE",
+		boolFalse: "FALSE",
+		maxParams: 32766,
+		features: features(
+			FeatureLimitOffset,
+			FeatureUpdateFrom,
+			FeatureUpdateLimit,
+			FeatureUpdateOffset,
+			FeatureOnConflict,
+			FeatureReturning,
+			FeatureFilter,
+			FeatureFrameGroups,
+			FeatureFrameExclude,
+			FeatureRecursiveKeyword,
+			FeatureCTEMaterialized,
+			FeatureDistinctFrom,
+			FeatureRowValues,
+			FeatureTableAliasAs,
+			FeatureSelectNoFrom,
+			FeatureMultiRowValues,
+			FeatureSelectExists,
+		),
+	}
+
+	// SQLServer is the Dialect for Microsoft SQL Server.
+	SQLServer Dialect = &dialect{
+		name:      "SQL Server",
+		format:    AtP,
+		quote:     [2]string{"[", "]"},
+		boolTrue:  sqlTrue,
+		boolFalse: sqlFalse,
+		maxParams: 2098, // 2100, less the statement and parameter definitions of sp_executesql
+		maxRows:   1000,
+		features: features(
+			FeatureOffsetFetch,
+			FeatureTop,
+			FeatureUpdateFrom,
+			FeatureOutput,
+			FeatureMerge,
+			FeatureMergeBySource,
+			FeatureUpdateFromJoin,
+			FeatureDeleteJoin,
+			FeatureLockHints,
+			FeatureDistinctFrom,
+			FeatureAnyAll,
+			FeatureTableAliasAs,
+			FeatureCompoundParens,
+			FeatureSelectNoFrom,
+			FeatureMergeTerminator,
+			FeatureMultiRowValues,
+			FeatureMergeWhenAnd,
+		),
+	}
+
+	// Oracle is the Dialect for Oracle Database.
+	Oracle Dialect = &dialect{
+		name:      "Oracle",
+		format:    Colon,
+		quote:     [2]string{`"`, `"`},
+		boolTrue:  sqlTrue,
+		boolFalse: sqlFalse,
+		maxParams: 65535,
+		features: features(
+			FeatureOffsetFetch,
+			FeatureMerge,
+			FeatureFrameExclude,
+			FeatureLockFor,
+			FeatureAnyAll,
+			FeatureCompoundParens,
+		),
+	}
+)
+
+type dialect struct {
+	name      string
+	format    PlaceholderFormat
+	quote     [2]string
+	boolTrue  string
+	boolFalse string
+	// maxParams is the maximum number of parameters of a statement, see
+	// InsertBuilder.ToSqlBatches.
+	maxParams int
+	// maxRows is the maximum number of rows of a VALUES clause, or 0 if it
+	// is only limited by maxParams.
+	maxRows  int
+	features [numFeatures]bool
+}
+
+func features(fs ...Feature) (set [numFeatures]bool) {
+	for _, f := range fs {
+		set[f] = true
+	}
+	return
+}
+
+func (d *dialect) Name() string {
+	return d.name
+}
+
+func (d *dialect) PlaceholderFormat() PlaceholderFormat {
+	return d.format
+}
+
+func (d *dialect) QuoteIdent(ident string) string {
+	escaped := strings.ReplaceAll(ident, d.quote[1], d.quote[1]+d.quote[1])
+	return d.quote[0] + escaped + d.quote[1]
+}
+
+func (d *dialect) BoolLiteral(v bool) string {
+	if v {
+		return d.boolTrue
+	}
+	return d.boolFalse
+}
+
+func (d *dialect) Supports(f Feature) bool {
+	return f >= 0 && f < numFeatures && d.features[f]
+}
+
+// ArrayFunc wraps a slice as an array value for a database driver, e.g.
+// pq.Array.
+type ArrayFunc func(slice any) any
+
+// WithArrayParams returns a Dialect like d, but which passes the slice values
+// of Eq, NotEq, In and NotIn as a single array parameter, rather than
+// expanding them into a list of parameters:
+//
+//	Select("*").From("users").Where(Eq{"id": ids}).Dialect(WithArrayParams(Postgres, pq.Array))
+//	// SELECT * FROM users WHERE id = ANY($1)
+//
+// so that the SQL doesn't depend on the length of the slice, and a long slice
+// doesn't exceed the database's limit on parameters. NotEq and NotIn render
+// "col <> ALL(?)". Empty slices are still rendered as a false (or true)
+// expression.
+//
+// wrap converts the slice to the array type of the driver. If it is nil, the
+// slice is passed as is, which drivers such as pgx support. Rendering a slice
+// is an error if d doesn't support FeatureArrayParams.
+func WithArrayParams(d Dialect, wrap ArrayFunc) Dialect {
+	if wrap == nil {
+		wrap = func(slice any) any { return slice }
+	}
+	if a, ok := d.(*arrayDialect); ok {
+		d = a.Dialect
+	}
+	return &arrayDialect{Dialect: d, wrap: wrap}
+}
+
+// arrayDialect is a Dialect which passes slices as array parameters.
+type arrayDialect struct {
+	Dialect
+	wrap ArrayFunc
+}
+
+// renderContext holds the statement-level settings which affect how nested
+// expressions are rendered.
+type renderContext struct {
+	dialect Dialect
+	format  PlaceholderFormat
+}
+
+// contextSqlizer is implemented by Sqlizers whose SQL depends on the statement
+// they are nested in.
+type contextSqlizer interface {
+	toSqlContext(rc renderContext) (string, []any, error)
+}
+
+func (rc renderContext) boolLiteral(v bool) string {
+	if rc.dialect == nil {
+		if v {
+			return sqlTrue
+		}
+		return sqlFalse
+	}
+	return rc.dialect.BoolLiteral(v)
+}
+
+func (rc renderContext) quoteIdent(ident string) string {
+	if rc.dialect == nil {
+		return `"` + strings.ReplaceAll(ident, `"`, `""`) + `"`
+	}
+	return rc.dialect.QuoteIdent(ident)
+}
+
+// arrayFunc returns the ArrayFunc used to pass a slice as an array parameter,
+// or nil if slices are expanded into a list of parameters.
+func (rc renderContext) arrayFunc() ArrayFunc {
+	if d, ok := rc.dialect.(*arrayDialect); ok {
+		return d.wrap
+	}
+	return nil
+}
+
+// baseDialect returns the built-in dialect of the context, unwrapping
+// WithArrayParams, or nil if there is none.
+func (rc renderContext) baseDialect() *dialect {
+	d := rc.dialect
+	if a, ok := d.(*arrayDialect); ok {
+		d = a.Dialect
+	}
+	base, _ := d.(*dialect)
+	return base
+}
+
+// maxParams returns the maximum number of parameters of a statement, or 0 if
+// it is unknown.
+func (rc renderContext) maxParams() int {
+	if d := rc.baseDialect(); d != nil {
+		return d.maxParams
+	}
+	return 0
+}
+
+// maxRows returns the maximum number of rows of a VALUES clause, or 0 if it
+// is unknown.
+func (rc renderContext) maxRows() int {
+	if rc.dialect != nil && !rc.dialect.Supports(FeatureMultiRowValues) {
+		return 1
+	}
+	if d := rc.baseDialect(); d != nil {
+		return d.maxRows
+	}
+	return 0
+}
+
+// backslashEscapes reports whether backslashes escape quotes in string
+// literals. Without a dialect, they are assumed to with the Question
+// PlaceholderFormat, which MySQL uses.
+func (rc renderContext) backslashEscapes() bool {
+	if rc.dialect == nil {
+		return rc.format == Question
+	}
+	return rc.dialect.Supports(FeatureBackslashEscapes)
+}
+
+// require returns an error if the dialect does not support the given feature.
+// All features are allowed when no dialect is set.
+func (rc renderContext) require(f Feature) error {
+	if rc.dialect == nil || rc.dialect.Supports(f) {
+		return nil
+	}
+	return fmt.Errorf("%s is not supported by %s", f, rc.dialect.Name())
+}
+
+// paginationDialect returns the dialect used to render LIMIT and OFFSET. If no
+// dialect is set, it is implied by the SQL Server and Oracle placeholder
+// formats, neither of which support LIMIT.
+func (rc renderContext) paginationDialect() Dialect {
+	if rc.dialect != nil {
+		return rc.dialect
+	}
+	switch rc.format {
+	case AtP:
+		return SQLServer
+	case Colon:
+		return Oracle
+	}
+	return nil
+}
+
+// selectLimit returns the clause to add after SELECT and the clause to add
+// after ORDER BY to limit the results of a SELECT.
+func (rc renderContext) selectLimit(limit, offset string, ordered bool) (top, tail string, err error) {
+	if len(limit) == 0 && len(offset) == 0 {
+		return
+	}
+
+	d := rc.paginationDialect()
+	switch {
+	case d == nil || d.Supports(FeatureLimitOffset):
+		if len(limit) > 0 {
+			tail += " LIMIT " + limit
+		}
+		if len(offset) > 0 {
+			tail += " OFFSET " + offset
+		}
+	case len(offset) == 0 && d.Supports(FeatureTop):
+		top = "TOP " + limit + " "
+	case d.Supports(FeatureOffsetFetch):
+		if len(offset) > 0 {
+			if !ordered {
+				err = fmt.Errorf("OFFSET requires ORDER BY on %s", d.Name())
+				return
+			}
+			tail += " OFFSET " + offset + " ROWS"
+		}
+		if len(limit) > 0 {
+			if len(offset) > 0 {
+				tail += " FETCH NEXT " + limit + " ROWS ONLY"
+			} else {
+				tail += " FETCH FIRST " + limit + " ROWS ONLY"
+			}
+		}
+	default:
+		err = fmt.Errorf("%s is not supported by %s", FeatureLimitOffset, d.Name())
+	}
+	return
+}
+
+// updateLimit is like selectLimit, but for UPDATE and DELETE statements.
+func (rc renderContext) updateLimit(limit, offset string, ordered bool) (top, tail string, err error) {
+	if len(limit) == 0 && len(offset) == 0 {
+		return
+	}
+
+	d := rc.paginationDialect()
+	switch {
+	case d == nil || d.Supports(FeatureUpdateLimit):
+		if len(offset) > 0 && d != nil && !d.Supports(FeatureUpdateOffset) {
+			err = fmt.Errorf("%s is not supported by %s", FeatureUpdateOffset, d.Name())
+			return
+		}
+		if len(limit) > 0 {
+			tail += " LIMIT " + limit
+		}
+		if len(offset) > 0 {
+			tail += " OFFSET " + offset
+		}
+	case d.Supports(FeatureTop):
+		switch {
+		case len(offset) > 0:
+			err = fmt.Errorf("%s is not supported by %s", FeatureUpdateOffset, d.Name())
+		case ordered:
+			err = fmt.Errorf("ORDER BY with TOP is not supported by %s", d.Name())
+		default:
+			top = "TOP (" + limit + ") "
+		}
+	default:
+		err = fmt.Errorf("%s is not supported by %s", FeatureUpdateLimit, d.Name())
+	}
+	return
+}
+
+// identExpr is a dialect-quoted identifier.
+type identExpr string
+
+// Ident returns an identifier quoted for the Dialect of the statement it is
+// used in, e.g. "order" for PostgreSQL or `order` for MySQL. Dots separate the
+// parts of qualified names, which are quoted individually.
+//
+// Ex:
+//
+//	Select().Column(Ident("order")).From("t").Dialect(MySQL) // SELECT `order` FROM t
+func Ident(name string) Sqlizer {
+	return identExpr(name)
+}
+
+func (e identExpr) ToSql() (string, []any, error) {
+	return e.toSqlContext(renderContext{})
+}
+
+func (e identExpr) toSqlContext(rc renderContext) (string, []any, error) {
+	parts := strings.Split(string(e), ".")
+	for i, p := range parts {
+		parts[i] = rc.quoteIdent(p)
+	}
+	return strings.Join(parts, "."), nil, nil
+}
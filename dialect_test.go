@@ -0,0 +1,274 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialectQuoteIdent(t *testing.T) {
+	testCases := []struct {
+		Dialect  Dialect
+		Expected string
+	}{
+		{Postgres, `SELECT "public"."order" FROM t`},
+		{MySQL, "SELECT `public`.`order` FROM t"},
+		{SQLite, `SELECT "public"."order" FROM t`},
+		{SQLServer, "SELECT [public].[order] FROM t"},
+		{Oracle, `SELECT "public"."order" FROM t`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Dialect.Name(), func(t *testing.T) {
+			sql, _, err := Select().Column(Ident("public.order")).From("t").Dialect(tc.Dialect).ToSql()
+			require.NoError(t, err)
+			require.Equal(t, tc.Expected, sql)
+		})
+	}
+
+	sql, _, err := Ident(`a"b`).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, `"a""b"`, sql)
+
+	require.Equal(t, "[a]]b]", SQLServer.QuoteIdent("a]b"))
+}
+
+func TestDialectBoolLiteral(t *testing.T) {
+	b := Select("a").From("t").Where(Eq{}).Where(Or{}).Where(Eq{"b": []int{}})
+
+	sql, _, err := b.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT a FROM t WHERE (1=1) AND (1=0) AND (1=0)", sql)
+
+	sql, _, err = b.Dialect(Postgres).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT a FROM t WHERE TRUE AND FALSE AND FALSE", sql)
+
+	sql, _, err = b.Dialect(SQLServer).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT a FROM t WHERE (1=1) AND (1=0) AND (1=0)", sql)
+}
+
+func TestDialectNestedInherit(t *testing.T) {
+	subQ := Select("id").From("u").Where(And{})
+	sql, _, err := Select("a").
+		FromSelect(subQ, "s").
+		Where(Expr("a IN (?)", Select("b").From("c").Where(NotEq{}))).
+		Dialect(MySQL).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT a FROM (SELECT id FROM u WHERE TRUE) AS s WHERE a IN (SELECT b FROM c WHERE TRUE)", sql)
+
+	// A nested query's own dialect takes precedence.
+	sql, _, err = Select("a").
+		Where(Expr("a IN (?)", Select("b").From("c").Where(And{}).Dialect(SQLServer))).
+		Dialect(MySQL).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT a WHERE a IN (SELECT b FROM c WHERE (1=1))", sql)
+}
+
+func TestDialectILike(t *testing.T) {
+	b := Select("a").From("t").Where(ILike{"a": "x%"})
+
+	sql, args, err := b.Dialect(Postgres).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT a FROM t WHERE a ILIKE $1", sql)
+	require.Equal(t, []any{"x%"}, args)
+
+	_, _, err = b.Dialect(MySQL).ToSql()
+	require.EqualError(t, err, "ILIKE is not supported by MySQL")
+
+	_, _, err = Delete("t").Where(Or{NotILike{"a": "x%"}}).Dialect(SQLite).ToSql()
+	require.EqualError(t, err, "ILIKE is not supported by SQLite")
+}
+
+func TestDialectUnsupported(t *testing.T) {
+	_, _, err := Update("a").Set("b", 1).From("c").Dialect(MySQL).ToSql()
+	require.EqualError(t, err, "UPDATE ... FROM is not supported by MySQL")
+
+	_, _, err = Update("a").Set("b", 1).From("c").Dialect(Postgres).ToSql()
+	require.NoError(t, err)
+}
+
+func TestDialectSelectLimit(t *testing.T) {
+	testCases := []struct {
+		Dialect  Dialect
+		Limit    bool
+		Offset   bool
+		Expected string
+	}{
+		{nil, true, true, "SELECT a FROM t ORDER BY a LIMIT 10 OFFSET 20"},
+		{Postgres, true, true, "SELECT a FROM t ORDER BY a LIMIT 10 OFFSET 20"},
+		{MySQL, true, false, "SELECT a FROM t ORDER BY a LIMIT 10"},
+		{SQLServer, true, false, "SELECT TOP 10 a FROM t ORDER BY a"},
+		{SQLServer, true, true, "SELECT a FROM t ORDER BY a OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY"},
+		{SQLServer, false, true, "SELECT a FROM t ORDER BY a OFFSET 20 ROWS"},
+		{Oracle, true, false, "SELECT a FROM t ORDER BY a FETCH FIRST 10 ROWS ONLY"},
+		{Oracle, true, true, "SELECT a FROM t ORDER BY a OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY"},
+	}
+
+	for _, tc := range testCases {
+		b := Select("a").From("t").OrderBy("a")
+		if tc.Dialect != nil {
+			b = b.Dialect(tc.Dialect)
+		}
+		if tc.Limit {
+			b = b.Limit(10)
+		}
+		if tc.Offset {
+			b = b.Offset(20)
+		}
+
+		sql, _, err := b.ToSql()
+		require.NoError(t, err)
+		require.Equal(t, tc.Expected, sql)
+	}
+}
+
+func TestDialectSelectLimitPlaceholderFormat(t *testing.T) {
+	sql, _, err := Select("a").Distinct().From("t").Where("b = ?", 1).Limit(5).PlaceholderFormat(AtP).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT DISTINCT TOP 5 a FROM t WHERE b = @p1", sql)
+
+	sql, _, err = Select("a").From("t").Where("b = ?", 1).Limit(5).PlaceholderFormat(Colon).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT a FROM t WHERE b = :1 FETCH FIRST 5 ROWS ONLY", sql)
+
+	// Nested queries follow the enclosing statement.
+	sql, _, err = Select("a").
+		FromSelect(Select("a").From("t").OrderBy("a").Offset(5).Limit(5), "s").
+		PlaceholderFormat(AtP).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT a FROM (SELECT a FROM t ORDER BY a OFFSET 5 ROWS FETCH NEXT 5 ROWS ONLY) AS s", sql)
+}
+
+func TestDialectSelectOffsetRequiresOrderBy(t *testing.T) {
+	_, _, err := Select("a").From("t").Offset(5).Dialect(SQLServer).ToSql()
+	require.EqualError(t, err, "OFFSET requires ORDER BY on SQL Server")
+
+	_, _, err = Select("a").From("t").Limit(5).Offset(5).PlaceholderFormat(Colon).ToSql()
+	require.EqualError(t, err, "OFFSET requires ORDER BY on Oracle")
+}
+
+func TestDialectUpdateDeleteLimit(t *testing.T) {
+	sql, _, err := Update("t").Set("a", 1).Limit(5).Dialect(SQLServer).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "UPDATE TOP (5) t SET a = @p1", sql)
+
+	sql, _, err = Delete("t").Where("a = ?", 1).Limit(5).PlaceholderFormat(AtP).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "DELETE TOP (5) FROM t WHERE a = @p1", sql)
+
+	sql, _, err = Delete("t").OrderBy("a").Limit(5).Dialect(MySQL).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "DELETE FROM t ORDER BY a LIMIT 5", sql)
+
+	sql, _, err = Update("t").Set("a", 1).Limit(5).Offset(2).Dialect(SQLite).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "UPDATE t SET a = ? LIMIT 5 OFFSET 2", sql)
+
+	_, _, err = Update("t").Set("a", 1).Limit(5).Offset(2).Dialect(MySQL).ToSql()
+	require.EqualError(t, err, "OFFSET on UPDATE/DELETE is not supported by MySQL")
+
+	_, _, err = Delete("t").Limit(5).Offset(2).Dialect(SQLServer).ToSql()
+	require.EqualError(t, err, "OFFSET on UPDATE/DELETE is not supported by SQL Server")
+
+	_, _, err = Delete("t").OrderBy("a").Limit(5).Dialect(SQLServer).ToSql()
+	require.EqualError(t, err, "ORDER BY with TOP is not supported by SQL Server")
+
+	_, _, err = Update("t").Set("a", 1).Limit(5).Dialect(Postgres).ToSql()
+	require.EqualError(t, err, "LIMIT on UPDATE/DELETE is not supported by PostgreSQL")
+
+	_, _, err = Delete("t").Limit(5).PlaceholderFormat(Colon).ToSql()
+	require.EqualError(t, err, "LIMIT on UPDATE/DELETE is not supported by Oracle")
+}
+
+func TestDialectPlaceholderFormat(t *testing.T) {
+	sb := StatementBuilder.Dialect(Postgres)
+
+	sql, _, err := sb.Select("a").From("t").Where(Eq{"b": 1}).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT a FROM t WHERE b = $1", sql)
+
+	sql, _, err = sb.Insert("t").Values(1).PlaceholderFormat(Question).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO t VALUES (?)", sql)
+
+	sql, _, err = StatementBuilder.Dialect(SQLServer).With().
+		As("c", Select("a").From("b").Where(Eq{})).
+		Delete("c").Where("a = ?", 1).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "WITH c AS ( SELECT a FROM b WHERE (1=1)) DELETE FROM c WHERE a = @p1", sql)
+}
+
+func TestDialectNil(t *testing.T) {
+	// A nil Dialect clears the dialect, but keeps the PlaceholderFormat.
+	sql, _, err := StatementBuilder.Dialect(Postgres).Dialect(nil).
+		Select("a").From("t").Where(Eq{"b": []int{}}).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT a FROM t WHERE (1=0)", sql)
+
+	sql, _, err = Select("a").From("t").Where(Eq{"b": 1}).Dialect(SQLServer).Dialect(nil).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT a FROM t WHERE b = @p1", sql)
+
+	builders := []Sqlizer{
+		Insert("t").Values(1).Dialect(nil),
+		Update("t").Set("a", 1).Dialect(nil),
+		Delete("t").Dialect(nil),
+		Merge("t").Using("s", "s").On("t.id = s.id").WhenMatched(nil).Delete().Dialect(nil),
+		UnionAll(Select("a").From("t")).Dialect(nil),
+		With().As("c", Select("a").From("t")).Dialect(nil).Select("a").From("c"),
+	}
+	for _, b := range builders {
+		_, _, err := b.ToSql()
+		require.NoError(t, err)
+	}
+}
+
+func TestFeatureString(t *testing.T) {
+	require.Equal(t, "ILIKE", FeatureILike.String())
+	require.Equal(t, "Feature(-1)", Feature(-1).String())
+}
+
+type testArray struct {
+	elems any
+}
+
+func TestDialectArrayParams(t *testing.T) {
+	d := WithArrayParams(Postgres, func(slice any) any { return testArray{slice} })
+	require.Equal(t, "PostgreSQL", d.Name())
+	require.Equal(t, Dollar, d.PlaceholderFormat())
+
+	sql, args, err := StatementBuilder.Dialect(d).
+		Select("*").From("users").
+		Where(Eq{"id": []int{1, 2, 3}}).
+		Where(NotEq{"status": []string{"banned"}}).
+		Where(In("org_id", []int64{7, 8})).
+		Where(NotIn("role", []string{})).
+		Where(Eq{"tag": []string{}}).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM users WHERE id = ANY($1) AND status <> ALL($2) AND org_id = ANY($3) AND TRUE AND FALSE", sql)
+	require.Equal(t, []any{testArray{[]int{1, 2, 3}}, testArray{[]string{"banned"}}, testArray{[]int64{7, 8}}}, args)
+
+	// A nested builder inherits the dialect.
+	sql, args, err = Select("*").From("users").
+		Where(InSelect("id", Select("user_id").From("orders").Where(Eq{"status": []string{"a", "b"}}))).
+		Dialect(WithArrayParams(d, nil)).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM users WHERE id IN (SELECT user_id FROM orders WHERE status = ANY($1))", sql)
+	require.Equal(t, []any{[]string{"a", "b"}}, args)
+
+	_, _, err = Select("*").From("users").Where(Eq{"id": []int{1}}).Dialect(WithArrayParams(MySQL, nil)).ToSql()
+	require.EqualError(t, err, "array parameters is not supported by MySQL")
+
+	// Without slices, the dialect is the same as the one it wraps.
+	sql, _, err = Select("*").From("users").Where(Eq{"id": 1}).Dialect(WithArrayParams(MySQL, nil)).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM users WHERE id = ?", sql)
+}
@@ -29,6 +29,10 @@ func Expr(sql string, args ...any) Sqlizer {
 }
 
 func (e expr) ToSql() (sql string, args []any, err error) {
+	return e.toSqlContext(renderContext{})
+}
+
+func (e expr) toSqlContext(rc renderContext) (sql string, args []any, err error) {
 	simple := true
 	for _, arg := range e.args {
 		if _, ok := arg.(Sqlizer); ok {
@@ -41,55 +45,133 @@ func (e expr) ToSql() (sql string, args []any, err error) {
 
 	b := &strings.Builder{}
 	ap := e.args
-	sp := e.sql
-
-	var isql string
-	var iargs []any
 
-	for err == nil && len(ap) > 0 && len(sp) > 0 {
-		i := strings.Index(sp, "?")
-		if i < 0 {
-			// no more placeholders
-			break
-		}
-		if len(sp) > i+1 && sp[i+1:i+2] == "?" {
-			// escaped "??"; append it and step past
-			b.WriteString(sp[:i+2])
-			sp = sp[i+2:]
-			continue
+	lexSql(e.sql, rc.backslashEscapes(), func(tok sqlToken, text string) {
+		if tok != sqlPlaceholder || len(ap) == 0 || err != nil {
+			// escaped "??" are left for ReplacePlaceholders
+			b.WriteString(text)
+			return
 		}
 
 		if as, ok := ap[0].(Sqlizer); ok {
 			// sqlizer argument; expand it and append the result
-			isql, iargs, err = nestedToSql(as)
-			b.WriteString(sp[:i])
+			var isql string
+			var iargs []any
+			isql, iargs, err = nestedToSql(rc, as)
 			b.WriteString(isql)
 			args = append(args, iargs...)
 		} else {
 			// normal argument; append it and the placeholder
-			b.WriteString(sp[:i+1])
+			b.WriteString(text)
 			args = append(args, ap[0])
 		}
 
-		// step past the argument and placeholder
+		// step past the argument
 		ap = ap[1:]
-		sp = sp[i+1:]
-	}
+	})
 
-	// append the remaining sql and arguments
-	b.WriteString(sp)
+	// append the remaining arguments
 	return b.String(), append(args, ap...), err
 }
 
+// Named holds the values of named parameters for NamedExpr.
+type Named map[string]any
+
+type namedExpr struct {
+	sql  string
+	args Named
+}
+
+// NamedExpr builds an expression from a SQL fragment with :name or @name
+// parameters and their values. Each parameter is replaced with a ? placeholder
+// and its value, so a value may be used more than once. Values may be
+// Sqlizers, which are expanded in place.
+//
+// It is an error for the fragment to use a name without a value, to leave a
+// value unused, or to contain ? placeholders.
+//
+// Ex:
+//
+//	NamedExpr("a = :id OR b = :id", Named{"id": 5})
+func NamedExpr(sql string, args Named) Sqlizer {
+	return namedExpr{sql: sql, args: args}
+}
+
+func (e namedExpr) ToSql() (sql string, args []any, err error) {
+	return e.toSqlContext(renderContext{})
+}
+
+func (e namedExpr) toSqlContext(rc renderContext) (sql string, args []any, err error) {
+	b := &strings.Builder{}
+	used := make(map[string]bool, len(e.args))
+
+	lexNamedSql(e.sql, rc.backslashEscapes(), func(tok sqlToken, text string) {
+		if err != nil {
+			return
+		}
+		switch tok {
+		case sqlPlaceholder:
+			err = fmt.Errorf("cannot use ? placeholders in a named expression")
+		case sqlNamed:
+			name := text[1:]
+			arg, ok := e.args[name]
+			if !ok {
+				err = fmt.Errorf("missing value for named parameter %q", name)
+				return
+			}
+			used[name] = true
+
+			if as, ok := arg.(Sqlizer); ok {
+				var isql string
+				var iargs []any
+				isql, iargs, err = nestedToSql(rc, as)
+				b.WriteString(isql)
+				args = append(args, iargs...)
+			} else {
+				b.WriteString("?")
+				args = append(args, arg)
+			}
+		default:
+			// escaped "??" are left for ReplacePlaceholders
+			b.WriteString(text)
+		}
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, name := range getSortedKeys(e.args) {
+		if !used[name] {
+			return "", nil, fmt.Errorf("unused named parameter %q", name)
+		}
+	}
+	return b.String(), args, nil
+}
+
+// namedPart returns a NamedExpr for a string predicate whose only argument is
+// Named, or nil.
+func namedPart(pred any, args []any) Sqlizer {
+	if sql, ok := pred.(string); ok && len(args) == 1 {
+		if named, ok := args[0].(Named); ok {
+			return NamedExpr(sql, named)
+		}
+	}
+	return nil
+}
+
 type concatExpr []any
 
 func (ce concatExpr) ToSql() (sql string, args []any, err error) {
+	return ce.toSqlContext(renderContext{})
+}
+
+func (ce concatExpr) toSqlContext(rc renderContext) (sql string, args []any, err error) {
 	for _, part := range ce {
 		switch p := part.(type) {
 		case string:
 			sql += p
 		case Sqlizer:
-			pSql, pArgs, err := nestedToSql(p)
+			pSql, pArgs, err := nestedToSql(rc, p)
 			if err != nil {
 				return "", nil, err
 			}
@@ -116,6 +198,9 @@ func ConcatExpr(parts ...any) Sqlizer {
 type aliasExpr struct {
 	expr  Sqlizer
 	alias string
+	// table is set for the alias of a derived table, which has no AS for
+	// dialects which don't accept it, such as Oracle.
+	table bool
 }
 
 // Alias allows to define alias for column in SelectBuilder. Useful when column is
@@ -124,24 +209,42 @@ type aliasExpr struct {
 //
 //	.Column(Alias(caseStmt, "case_column"))
 func Alias(expr Sqlizer, alias string) Sqlizer {
-	return aliasExpr{expr, alias}
+	return aliasExpr{expr: expr, alias: alias}
+}
+
+// derivedTable aliases a subquery in a FROM or JOIN clause.
+func derivedTable(expr Sqlizer, alias string) Sqlizer {
+	return aliasExpr{expr: expr, alias: alias, table: true}
 }
 
 func (e aliasExpr) ToSql() (sql string, args []any, err error) {
-	sql, args, err = nestedToSql(e.expr)
-	if err == nil {
+	return e.toSqlContext(renderContext{})
+}
+
+func (e aliasExpr) toSqlContext(rc renderContext) (sql string, args []any, err error) {
+	sql, args, err = nestedToSql(rc, e.expr)
+	if err != nil {
+		return
+	}
+	if e.table && rc.dialect != nil && !rc.dialect.Supports(FeatureTableAliasAs) {
+		sql = fmt.Sprintf("(%s) %s", sql, e.alias)
+	} else {
 		sql = fmt.Sprintf("(%s) AS %s", sql, e.alias)
 	}
 	return
 }
 
 // Eq is syntactic sugar for use with Where/Having/Set methods.
+//
+// Slice values are rendered as IN lists, or array parameters with
+// WithArrayParams, nil values as IS NULL, and subquery values (SelectBuilder
+// or CompoundBuilder) are parenthesized.
 type Eq map[string]any
 
-func (eq Eq) toSQL(useNotOpr bool) (sql string, args []any, err error) {
+func (eq Eq) toSQL(rc renderContext, useNotOpr bool) (sql string, args []any, err error) {
 	if len(eq) == 0 {
 		// Empty Sql{} evaluates to true.
-		sql = sqlTrue
+		sql = rc.boolLiteral(true)
 		return
 	}
 
@@ -149,15 +252,17 @@ func (eq Eq) toSQL(useNotOpr bool) (sql string, args []any, err error) {
 		exprs       []string
 		equalOpr    = "="
 		inOpr       = "IN"
+		arrayOpr    = "= ANY"
 		nullOpr     = "IS"
-		inEmptyExpr = sqlFalse
+		inEmptyExpr = rc.boolLiteral(false)
 	)
 
 	if useNotOpr {
 		equalOpr = "<>"
 		inOpr = "NOT IN"
+		arrayOpr = "<> ALL"
 		nullOpr = "IS NOT"
-		inEmptyExpr = sqlTrue
+		inEmptyExpr = rc.boolLiteral(true)
 	}
 
 	sortedKeys := getSortedKeys(eq)
@@ -185,7 +290,7 @@ func (eq Eq) toSQL(useNotOpr bool) (sql string, args []any, err error) {
 			expr = fmt.Sprintf("%s %s NULL", key, nullOpr)
 		} else {
 			if p, ok := val.(Sqlizer); ok {
-				pSql, pArgs, err := nestedToSql(p)
+				pSql, pArgs, err := subqueryToSql(rc, p)
 				if err != nil {
 					return "", nil, err
 				}
@@ -202,6 +307,12 @@ func (eq Eq) toSQL(useNotOpr bool) (sql string, args []any, err error) {
 					if args == nil {
 						args = []any{}
 					}
+				} else if wrap := rc.arrayFunc(); wrap != nil {
+					if err = rc.require(FeatureArrayParams); err != nil {
+						return
+					}
+					expr = fmt.Sprintf("%s %s(?)", key, arrayOpr)
+					args = append(args, wrap(val))
 				} else {
 					for i := 0; i < valVal.Len(); i++ {
 						args = append(args, valVal.Index(i).Interface())
@@ -220,7 +331,11 @@ func (eq Eq) toSQL(useNotOpr bool) (sql string, args []any, err error) {
 }
 
 func (eq Eq) ToSql() (sql string, args []any, err error) {
-	return eq.toSQL(false)
+	return eq.toSQL(renderContext{}, false)
+}
+
+func (eq Eq) toSqlContext(rc renderContext) (sql string, args []any, err error) {
+	return eq.toSQL(rc, false)
 }
 
 // NotEq is syntactic sugar for use with Where/Having/Set methods.
@@ -230,7 +345,11 @@ func (eq Eq) ToSql() (sql string, args []any, err error) {
 type NotEq Eq
 
 func (neq NotEq) ToSql() (sql string, args []any, err error) {
-	return Eq(neq).toSQL(true)
+	return Eq(neq).toSQL(renderContext{}, true)
+}
+
+func (neq NotEq) toSqlContext(rc renderContext) (sql string, args []any, err error) {
+	return Eq(neq).toSQL(rc, true)
 }
 
 // Like is syntactic sugar for use with LIKE conditions.
@@ -239,10 +358,12 @@ func (neq NotEq) ToSql() (sql string, args []any, err error) {
 //	.Where(Like{"name": "%irrel"})
 type Like map[string]any
 
-func (lk Like) toSql(opr string) (sql string, args []any, err error) {
+func (lk Like) toSql(rc renderContext, opr string) (sql string, args []any, err error) {
 	var exprs []string
-	for key, val := range lk {
+	sortedKeys := getSortedKeys(lk)
+	for _, key := range sortedKeys {
 		expr := ""
+		val := lk[key]
 
 		switch v := val.(type) {
 		case driver.Valuer:
@@ -256,7 +377,7 @@ func (lk Like) toSql(opr string) (sql string, args []any, err error) {
 			return
 		} else {
 			if p, ok := val.(Sqlizer); ok {
-				pSql, pArgs, err := nestedToSql(p)
+				pSql, pArgs, err := subqueryToSql(rc, p)
 				if err != nil {
 					return "", nil, err
 				}
@@ -277,7 +398,11 @@ func (lk Like) toSql(opr string) (sql string, args []any, err error) {
 }
 
 func (lk Like) ToSql() (sql string, args []any, err error) {
-	return lk.toSql("LIKE")
+	return lk.toSql(renderContext{}, "LIKE")
+}
+
+func (lk Like) toSqlContext(rc renderContext) (sql string, args []any, err error) {
+	return lk.toSql(rc, "LIKE")
 }
 
 // NotLike is syntactic sugar for use with LIKE conditions.
@@ -287,7 +412,11 @@ func (lk Like) ToSql() (sql string, args []any, err error) {
 type NotLike Like
 
 func (nlk NotLike) ToSql() (sql string, args []any, err error) {
-	return Like(nlk).toSql("NOT LIKE")
+	return Like(nlk).toSql(renderContext{}, "NOT LIKE")
+}
+
+func (nlk NotLike) toSqlContext(rc renderContext) (sql string, args []any, err error) {
+	return Like(nlk).toSql(rc, "NOT LIKE")
 }
 
 // ILike is syntactic sugar for use with ILIKE conditions.
@@ -297,7 +426,14 @@ func (nlk NotLike) ToSql() (sql string, args []any, err error) {
 type ILike Like
 
 func (ilk ILike) ToSql() (sql string, args []any, err error) {
-	return Like(ilk).toSql("ILIKE")
+	return ilk.toSqlContext(renderContext{})
+}
+
+func (ilk ILike) toSqlContext(rc renderContext) (sql string, args []any, err error) {
+	if err = rc.require(FeatureILike); err != nil {
+		return
+	}
+	return Like(ilk).toSql(rc, "ILIKE")
 }
 
 // NotILike is syntactic sugar for use with ILIKE conditions.
@@ -307,7 +443,14 @@ func (ilk ILike) ToSql() (sql string, args []any, err error) {
 type NotILike Like
 
 func (nilk NotILike) ToSql() (sql string, args []any, err error) {
-	return Like(nilk).toSql("NOT ILIKE")
+	return nilk.toSqlContext(renderContext{})
+}
+
+func (nilk NotILike) toSqlContext(rc renderContext) (sql string, args []any, err error) {
+	if err = rc.require(FeatureILike); err != nil {
+		return
+	}
+	return Like(nilk).toSql(rc, "NOT ILIKE")
 }
 
 // Lt is syntactic sugar for use with Where/Having/Set methods.
@@ -316,7 +459,7 @@ func (nilk NotILike) ToSql() (sql string, args []any, err error) {
 //	.Where(Lt{"id": 1})
 type Lt map[string]any
 
-func (lt Lt) toSql(opposite, orEq bool) (sql string, args []any, err error) {
+func (lt Lt) toSql(rc renderContext, opposite, orEq bool) (sql string, args []any, err error) {
 	var (
 		exprs []string
 		opr   = "<"
@@ -347,7 +490,7 @@ func (lt Lt) toSql(opposite, orEq bool) (sql string, args []any, err error) {
 			return
 		}
 		if p, ok := val.(Sqlizer); ok {
-			pSql, pArgs, err := nestedToSql(p)
+			pSql, pArgs, err := subqueryToSql(rc, p)
 			if err != nil {
 				return "", nil, err
 			}
@@ -368,7 +511,11 @@ func (lt Lt) toSql(opposite, orEq bool) (sql string, args []any, err error) {
 }
 
 func (lt Lt) ToSql() (sql string, args []any, err error) {
-	return lt.toSql(false, false)
+	return lt.toSql(renderContext{}, false, false)
+}
+
+func (lt Lt) toSqlContext(rc renderContext) (sql string, args []any, err error) {
+	return lt.toSql(rc, false, false)
 }
 
 // LtOrEq is syntactic sugar for use with Where/Having/Set methods.
@@ -378,7 +525,11 @@ func (lt Lt) ToSql() (sql string, args []any, err error) {
 type LtOrEq Lt
 
 func (ltOrEq LtOrEq) ToSql() (sql string, args []any, err error) {
-	return Lt(ltOrEq).toSql(false, true)
+	return Lt(ltOrEq).toSql(renderContext{}, false, true)
+}
+
+func (ltOrEq LtOrEq) toSqlContext(rc renderContext) (sql string, args []any, err error) {
+	return Lt(ltOrEq).toSql(rc, false, true)
 }
 
 // Gt is syntactic sugar for use with Where/Having/Set methods.
@@ -388,7 +539,11 @@ func (ltOrEq LtOrEq) ToSql() (sql string, args []any, err error) {
 type Gt Lt
 
 func (gt Gt) ToSql() (sql string, args []any, err error) {
-	return Lt(gt).toSql(true, false)
+	return Lt(gt).toSql(renderContext{}, true, false)
+}
+
+func (gt Gt) toSqlContext(rc renderContext) (sql string, args []any, err error) {
+	return Lt(gt).toSql(rc, true, false)
 }
 
 // GtOrEq is syntactic sugar for use with Where/Having/Set methods.
@@ -398,18 +553,22 @@ func (gt Gt) ToSql() (sql string, args []any, err error) {
 type GtOrEq Lt
 
 func (gtOrEq GtOrEq) ToSql() (sql string, args []any, err error) {
-	return Lt(gtOrEq).toSql(true, true)
+	return Lt(gtOrEq).toSql(renderContext{}, true, true)
+}
+
+func (gtOrEq GtOrEq) toSqlContext(rc renderContext) (sql string, args []any, err error) {
+	return Lt(gtOrEq).toSql(rc, true, true)
 }
 
 type conj []Sqlizer
 
-func (c conj) join(sep, defaultExpr string) (sql string, args []any, err error) {
+func (c conj) join(rc renderContext, sep string, defaultValue bool) (sql string, args []any, err error) {
 	if len(c) == 0 {
-		return defaultExpr, []any{}, nil
+		return rc.boolLiteral(defaultValue), []any{}, nil
 	}
 	var sqlParts []string
 	for _, sqlizer := range c {
-		partSQL, partArgs, err := nestedToSql(sqlizer)
+		partSQL, partArgs, err := nestedToSql(rc, sqlizer)
 		if err != nil {
 			return "", nil, err
 		}
@@ -428,14 +587,22 @@ func (c conj) join(sep, defaultExpr string) (sql string, args []any, err error)
 type And conj
 
 func (a And) ToSql() (string, []any, error) {
-	return conj(a).join(" AND ", sqlTrue)
+	return conj(a).join(renderContext{}, " AND ", true)
+}
+
+func (a And) toSqlContext(rc renderContext) (string, []any, error) {
+	return conj(a).join(rc, " AND ", true)
 }
 
 // Or conjunction Sqlizers
 type Or conj
 
 func (o Or) ToSql() (string, []any, error) {
-	return conj(o).join(" OR ", sqlFalse)
+	return conj(o).join(renderContext{}, " OR ", false)
+}
+
+func (o Or) toSqlContext(rc renderContext) (string, []any, error) {
+	return conj(o).join(rc, " OR ", false)
 }
 
 func getSortedKeys(exp map[string]any) []string {
@@ -397,6 +397,16 @@ func TestLikeToSql(t *testing.T) {
 	require.Equal(t, expectedArgs, args)
 }
 
+func TestLikeSortedToSql(t *testing.T) {
+	b := Like{"name": "%irrel", "email": "%@example.com", "city": "A%"}
+	for i := 0; i < 10; i++ {
+		sql, args, err := b.ToSql()
+		require.NoError(t, err)
+		require.Equal(t, "city LIKE ? AND email LIKE ? AND name LIKE ?", sql)
+		require.Equal(t, []any{"A%", "%@example.com", "%irrel"}, args)
+	}
+}
+
 func TestLikeSqlizeToSql(t *testing.T) {
 	b := Like{"name": Expr("CONCAT(test, ?)", "%irrel")}
 	sql, args, err := b.ToSql()
@@ -517,6 +527,65 @@ func TestExprRecursion(t *testing.T) {
 	}
 }
 
+func TestNamedExpr(t *testing.T) {
+	b := NamedExpr("a = :id OR b = @id OR c::text = :name", Named{"id": 5, "name": "x"})
+	sql, args, err := b.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "a = ? OR b = ? OR c::text = ?", sql)
+	require.Equal(t, []any{5, 5, "x"}, args)
+}
+
+func TestNamedExprSkipsLiteralsAndComments(t *testing.T) {
+	b := NamedExpr("a = ':id' AND b = :id -- :other\n AND @@version > 1 AND arr[lo:hi] = :id", Named{"id": 1})
+	sql, args, err := b.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "a = ':id' AND b = ? -- :other\n AND @@version > 1 AND arr[lo:hi] = ?", sql)
+	require.Equal(t, []any{1, 1}, args)
+}
+
+func TestNamedExprSqlizer(t *testing.T) {
+	b := NamedExpr("x IN (:sub) AND y = :y", Named{
+		"sub": Select("id").From("t").Where("z = ?", 1),
+		"y":   2,
+	})
+	sql, args, err := b.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "x IN (SELECT id FROM t WHERE z = ?) AND y = ?", sql)
+	require.Equal(t, []any{1, 2}, args)
+}
+
+func TestNamedExprErrors(t *testing.T) {
+	_, _, err := NamedExpr("a = :a AND b = :b", Named{"a": 1}).ToSql()
+	require.EqualError(t, err, `missing value for named parameter "b"`)
+
+	_, _, err = NamedExpr("a = :a", Named{"a": 1, "b": 2, "c": 3}).ToSql()
+	require.EqualError(t, err, `unused named parameter "b"`)
+
+	_, _, err = NamedExpr("a = :a AND b = ?", Named{"a": 1}).ToSql()
+	require.EqualError(t, err, "cannot use ? placeholders in a named expression")
+}
+
+func TestNamedWhereHavingColumn(t *testing.T) {
+	for _, format := range []PlaceholderFormat{Question, Dollar, Colon, AtP} {
+		sql, args, err := Select("id").
+			Column("COALESCE(nickname, :default) AS name", Named{"default": "anon"}).
+			From("users").
+			Where("org_id = :org AND (owner_id = :user OR creator_id = :user)", Named{"org": 1, "user": 2}).
+			Where("enabled = ?", true).
+			GroupBy("id").
+			Having("COUNT(*) > :n", Named{"n": 3}).
+			PlaceholderFormat(format).
+			ToSql()
+		require.NoError(t, err)
+
+		expected, err := format.ReplacePlaceholders("SELECT id, COALESCE(nickname, ?) AS name FROM users " +
+			"WHERE org_id = ? AND (owner_id = ? OR creator_id = ?) AND enabled = ? GROUP BY id HAVING COUNT(*) > ?")
+		require.NoError(t, err)
+		require.Equal(t, expected, sql)
+		require.Equal(t, []any{"anon", 1, 2, 2, true, 3}, args)
+	}
+}
+
 func ExampleEq() {
 	Select("id", "created", "first_name").From("users").Where(Eq{
 		"company": 20,
@@ -1,9 +1,12 @@
 package sq
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"io"
+	"reflect"
 	"sort"
 	"strings"
 
@@ -11,18 +14,72 @@ import (
 )
 
 type insertData struct {
-	PlaceholderFormat PlaceholderFormat
-	Prefixes          []Sqlizer
-	StatementKeyword  string
-	Options           []string
-	Into              string
-	Columns           []string
-	Values            [][]any
-	Suffixes          []Sqlizer
-	Select            *SelectBuilder
+	PlaceholderFormat        PlaceholderFormat
+	Dialect                  Dialect
+	RunWith                  Runner
+	Prefixes                 []Sqlizer
+	WithParts                []withPart
+	StatementKeyword         string
+	Options                  []string
+	Into                     string
+	Columns                  []string
+	Values                   [][]any
+	Suffixes                 []Sqlizer
+	Select                   *SelectBuilder
+	OnConflict               bool
+	ConflictColumns          []string
+	ConflictConstraint       string
+	ConflictWhereParts       []Sqlizer
+	ConflictDoNothing        bool
+	ConflictSetClauses       []setClause
+	ConflictUpdateWhereParts []Sqlizer
+	DuplicateKeySetClauses   []setClause
+	Returning                []returningPart
+	// Err is an error from setting the values of the query, which is returned
+	// when it is rendered, unless the values are replaced.
+	Err error
+}
+
+func (d *insertData) ExecContext(ctx context.Context) (sql.Result, error) {
+	if d.RunWith == nil {
+		return nil, ErrRunnerNotSet
+	}
+	return ExecContextWith(ctx, d.RunWith, d)
+}
+
+func (d *insertData) QueryContext(ctx context.Context) (*sql.Rows, error) {
+	if d.RunWith == nil {
+		return nil, ErrRunnerNotSet
+	}
+	return QueryContextWith(ctx, d.RunWith, d)
+}
+
+func (d *insertData) QueryRowContext(ctx context.Context) RowScanner {
+	if d.RunWith == nil {
+		return &Row{err: ErrRunnerNotSet}
+	}
+	return QueryRowContextWith(ctx, d.RunWith, d)
 }
 
 func (d *insertData) ToSql() (sqlStr string, args []any, err error) {
+	sqlStr, args, err = d.ToSqlRaw()
+	if err != nil {
+		return
+	}
+
+	sqlStr, args, err = replacePlaceholders(d.PlaceholderFormat, sqlStr, args)
+	return
+}
+
+func (d *insertData) ToSqlRaw() (sqlStr string, args []any, err error) {
+	return d.toSqlRaw(renderContext{dialect: d.Dialect, format: d.PlaceholderFormat})
+}
+
+func (d *insertData) toSqlRaw(rc renderContext) (sqlStr string, args []any, err error) {
+	if d.Err != nil {
+		err = d.Err
+		return
+	}
 	if len(d.Into) == 0 {
 		err = errors.New("insert statements must specify a table")
 		return
@@ -35,7 +92,7 @@ func (d *insertData) ToSql() (sqlStr string, args []any, err error) {
 	sql := &strings.Builder{}
 
 	if len(d.Prefixes) > 0 {
-		args, err = appendToSql(d.Prefixes, sql, " ", args)
+		args, err = appendToSql(rc, d.Prefixes, sql, " ", args)
 		if err != nil {
 			return
 		}
@@ -43,6 +100,13 @@ func (d *insertData) ToSql() (sqlStr string, args []any, err error) {
 		sql.WriteString(" ")
 	}
 
+	if len(d.WithParts) > 0 {
+		args, err = appendWithToSql(rc, d.WithParts, sql, args)
+		if err != nil {
+			return
+		}
+	}
+
 	if d.StatementKeyword == "" {
 		sql.WriteString("INSERT ")
 	} else {
@@ -65,31 +129,58 @@ func (d *insertData) ToSql() (sqlStr string, args []any, err error) {
 		sql.WriteString(") ")
 	}
 
+	returning, returningArgs, err := returningToSql(rc, d.Returning, "INSERTED")
+	if err != nil {
+		return
+	}
+	output := rc.useOutput()
+	if output && len(returning) > 0 {
+		sql.WriteString(returning)
+		sql.WriteString(" ")
+		args = append(args, returningArgs...)
+	}
+
 	if d.Select != nil {
-		args, err = d.appendSelectToSQL(sql, args)
+		args, err = d.appendSelectToSQL(rc, sql, args)
 	} else {
-		args, err = d.appendValuesToSQL(sql, args)
+		args, err = d.appendValuesToSQL(rc, sql, args)
 	}
 	if err != nil {
 		return
 	}
 
+	args, err = d.appendUpsertToSQL(rc, sql, args)
+	if err != nil {
+		return
+	}
+
+	if !output && len(returning) > 0 {
+		sql.WriteString(" ")
+		sql.WriteString(returning)
+		args = append(args, returningArgs...)
+	}
+
 	if len(d.Suffixes) > 0 {
 		sql.WriteString(" ")
-		args, err = appendToSql(d.Suffixes, sql, " ", args)
+		args, err = appendToSql(rc, d.Suffixes, sql, " ", args)
 		if err != nil {
 			return
 		}
 	}
 
-	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sql.String())
+	sqlStr = sql.String()
 	return
 }
 
-func (d *insertData) appendValuesToSQL(w io.Writer, args []any) ([]any, error) {
+func (d *insertData) appendValuesToSQL(rc renderContext, w io.Writer, args []any) ([]any, error) {
 	if len(d.Values) == 0 {
 		return args, errors.New("values for insert statements are not set")
 	}
+	if len(d.Values) > 1 {
+		if err := rc.require(FeatureMultiRowValues); err != nil {
+			return nil, err
+		}
+	}
 
 	_, err := io.WriteString(w, "VALUES ")
 	if err != nil {
@@ -101,7 +192,7 @@ func (d *insertData) appendValuesToSQL(w io.Writer, args []any) ([]any, error) {
 		valueStrings := make([]string, len(row))
 		for v, val := range row {
 			if vs, ok := val.(Sqlizer); ok {
-				vsql, vargs, err := nestedToSql(vs)
+				vsql, vargs, err := nestedToSql(rc, vs)
 				if err != nil {
 					return nil, err
 				}
@@ -123,12 +214,12 @@ func (d *insertData) appendValuesToSQL(w io.Writer, args []any) ([]any, error) {
 	return args, nil
 }
 
-func (d *insertData) appendSelectToSQL(w io.Writer, args []any) ([]any, error) {
+func (d *insertData) appendSelectToSQL(rc renderContext, w io.Writer, args []any) ([]any, error) {
 	if d.Select == nil {
 		return args, errors.New("select clause for insert statements are not set")
 	}
 
-	selectClause, sArgs, err := nestedToSql(d.Select)
+	selectClause, sArgs, err := nestedToSql(rc, d.Select)
 	if err != nil {
 		return args, err
 	}
@@ -142,6 +233,96 @@ func (d *insertData) appendSelectToSQL(w io.Writer, args []any) ([]any, error) {
 	return args, nil
 }
 
+func (d *insertData) appendUpsertToSQL(rc renderContext, w io.Writer, args []any) ([]any, error) {
+	if !d.OnConflict {
+		if len(d.ConflictSetClauses) > 0 || d.ConflictDoNothing {
+			return nil, errors.New("DoUpdateSet and DoNothing require OnConflict or OnConstraint")
+		}
+		if len(d.ConflictWhereParts) > 0 || len(d.ConflictUpdateWhereParts) > 0 {
+			return nil, errors.New("OnConflictWhere and DoUpdateWhere require OnConflict or OnConstraint")
+		}
+	} else {
+		if err := rc.require(FeatureOnConflict); err != nil {
+			return nil, err
+		}
+		if len(d.ConflictWhereParts) > 0 && len(d.ConflictColumns) == 0 {
+			return nil, errors.New("OnConflictWhere requires conflict columns")
+		}
+
+		sql := &strings.Builder{}
+		sql.WriteString(" ON CONFLICT")
+
+		switch {
+		case len(d.ConflictConstraint) > 0:
+			if err := rc.require(FeatureOnConstraint); err != nil {
+				return nil, err
+			}
+			sql.WriteString(" ON CONSTRAINT ")
+			sql.WriteString(d.ConflictConstraint)
+		case len(d.ConflictColumns) > 0:
+			sql.WriteString(" (")
+			sql.WriteString(strings.Join(d.ConflictColumns, ", "))
+			sql.WriteString(")")
+
+			if len(d.ConflictWhereParts) > 0 {
+				var err error
+				sql.WriteString(" WHERE ")
+				args, err = appendToSql(rc, d.ConflictWhereParts, sql, " AND ", args)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		switch {
+		case len(d.ConflictSetClauses) > 0:
+			if len(d.ConflictColumns) == 0 && len(d.ConflictConstraint) == 0 {
+				return nil, errors.New("DoUpdateSet requires conflict columns or a constraint")
+			}
+
+			var err error
+			sql.WriteString(" DO UPDATE SET ")
+			args, err = appendSetClauses(rc, d.ConflictSetClauses, sql, args)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(d.ConflictUpdateWhereParts) > 0 {
+				sql.WriteString(" WHERE ")
+				args, err = appendToSql(rc, d.ConflictUpdateWhereParts, sql, " AND ", args)
+				if err != nil {
+					return nil, err
+				}
+			}
+		case d.ConflictDoNothing:
+			sql.WriteString(" DO NOTHING")
+		default:
+			return nil, errors.New("OnConflict requires DoNothing or DoUpdateSet")
+		}
+
+		if _, err := io.WriteString(w, sql.String()); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(d.DuplicateKeySetClauses) > 0 {
+		if err := rc.require(FeatureOnDuplicateKey); err != nil {
+			return nil, err
+		}
+
+		_, err := io.WriteString(w, " ON DUPLICATE KEY UPDATE ")
+		if err != nil {
+			return nil, err
+		}
+		args, err = appendSetClauses(rc, d.DuplicateKeySetClauses, w, args)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return args, nil
+}
+
 // Builder
 
 // InsertBuilder builds SQL INSERT statements.
@@ -159,6 +340,45 @@ func (b InsertBuilder) PlaceholderFormat(f PlaceholderFormat) InsertBuilder {
 	return builder.Set(b, "PlaceholderFormat", f).(InsertBuilder)
 }
 
+// Dialect sets the Dialect (e.g. Postgres or MySQL) for the query, and sets the
+// PlaceholderFormat to the dialect's default. A nil Dialect clears it, keeping
+// the PlaceholderFormat.
+func (b InsertBuilder) Dialect(d Dialect) InsertBuilder {
+	if d == nil {
+		return builder.Delete(b, "Dialect").(InsertBuilder)
+	}
+	b = builder.Set(b, "Dialect", d).(InsertBuilder)
+	return b.PlaceholderFormat(d.PlaceholderFormat())
+}
+
+// Runner methods
+
+// RunWith sets a Runner (like database/sql.DB) to be used with e.g.
+// ExecContext.
+func (b InsertBuilder) RunWith(runner Runner) InsertBuilder {
+	return builder.Set(b, "RunWith", runner).(InsertBuilder)
+}
+
+// ExecContext builds and ExecContexts the query with the Runner set by RunWith.
+func (b InsertBuilder) ExecContext(ctx context.Context) (sql.Result, error) {
+	data := builder.GetStruct(b).(insertData)
+	return data.ExecContext(ctx)
+}
+
+// QueryContext builds and QueryContexts the query with the Runner set by
+// RunWith.
+func (b InsertBuilder) QueryContext(ctx context.Context) (*sql.Rows, error) {
+	data := builder.GetStruct(b).(insertData)
+	return data.QueryContext(ctx)
+}
+
+// QueryRowContext builds and QueryRowContexts the query with the Runner set by
+// RunWith.
+func (b InsertBuilder) QueryRowContext(ctx context.Context) RowScanner {
+	data := builder.GetStruct(b).(insertData)
+	return data.QueryRowContext(ctx)
+}
+
 // SQL methods
 
 // ToSql builds the query into a SQL string and bound args.
@@ -167,6 +387,43 @@ func (b InsertBuilder) ToSql() (string, []any, error) {
 	return data.ToSql()
 }
 
+// ToSqlRaw builds the query into a SQL string and bound args without
+// replacing the placeholders, for use in nested queries.
+func (b InsertBuilder) ToSqlRaw() (string, []any, error) {
+	data := builder.GetStruct(b).(insertData)
+	return data.ToSqlRaw()
+}
+
+// toSqlContext builds a nested query, inheriting the Dialect of the enclosing
+// statement if none is set.
+func (b InsertBuilder) toSqlContext(rc renderContext) (string, []any, error) {
+	data := builder.GetStruct(b).(insertData)
+	if data.Dialect != nil {
+		rc.dialect = data.Dialect
+	}
+	return data.toSqlRaw(rc)
+}
+
+// With adds a common table expression to the WITH clause of the query.
+//
+// See WithBuilder.As.
+func (b InsertBuilder) With(alias string, sql Sqlizer, opts ...CTEOption) InsertBuilder {
+	return builder.Append(b, "WithParts", newWithPart(alias, sql, opts)).(InsertBuilder)
+}
+
+// WithRecursive adds a recursive common table expression to the WITH clause
+// of the query.
+//
+// See WithBuilder.Recursive.
+func (b InsertBuilder) WithRecursive(alias string, anchor, recursive SelectBuilder, opts ...CTEOption) InsertBuilder {
+	return builder.Append(b, "WithParts", newRecursiveWithPart(alias, anchor, recursive, opts)).(InsertBuilder)
+}
+
+// RemoveWith removes the WITH clause.
+func (b InsertBuilder) RemoveWith() InsertBuilder {
+	return builder.Delete(b, "WithParts").(InsertBuilder)
+}
+
 // Prefix adds an expression to the beginning of the query
 func (b InsertBuilder) Prefix(sql string, args ...any) InsertBuilder {
 	return b.PrefixExpr(Expr(sql, args...))
@@ -222,10 +479,74 @@ func (b InsertBuilder) SetMap(clauses map[string]any) InsertBuilder {
 		vals = append(vals, clauses[col])
 	}
 
+	return b.setValues(cols, [][]any{vals})
+}
+
+// setValues replaces the columns and values of the query, clearing the error
+// of previous values.
+func (b InsertBuilder) setValues(cols []string, values [][]any) InsertBuilder {
 	b = builder.Set(b, "Columns", cols).(InsertBuilder)
-	b = builder.Set(b, "Values", [][]any{vals}).(InsertBuilder)
+	b = builder.Set(b, "Values", values).(InsertBuilder)
+	return builder.Delete(b, "Err").(InsertBuilder)
+}
 
-	return b
+// SetStruct sets columns and values for insert builder from the db tagged
+// fields of a struct (or pointer to struct), in declaration order.
+//
+// Fields tagged readonly are skipped, as are fields tagged omitempty which have
+// their zero value. Like SetMap, it resets any previously set columns and
+// values. See Get for how fields are mapped to columns.
+//
+// SetStruct panics if v is not a struct or a pointer to a struct.
+func (b InsertBuilder) SetStruct(v any) InsertBuilder {
+	rv, info := mustStructValue(v)
+	cols, vals := info.setColumns(rv, false, true)
+
+	return b.setValues(cols, [][]any{vals})
+}
+
+// ValuesStructs sets columns and values for insert builder from a slice of
+// structs (or pointers to structs), one row per element.
+//
+// Fields tagged readonly are skipped. As every row must have the same columns,
+// the omitempty option is ignored. Like SetMap, it resets any previously set
+// columns and values.
+//
+// ValuesStructs panics if rows is not a slice of structs or pointers to
+// structs. A nil element is an error when the query is rendered.
+func (b InsertBuilder) ValuesStructs(rows any) InsertBuilder {
+	rv := reflect.ValueOf(rows)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		panic(fmt.Sprintf("expected slice of structs, not %T", rows))
+	}
+
+	et := rv.Type().Elem()
+	if et.Kind() == reflect.Ptr {
+		et = et.Elem()
+	}
+	if et.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("expected slice of structs, not %T", rows))
+	}
+
+	info, err := getStructInfo(et)
+	if err != nil {
+		panic(err)
+	}
+
+	var cols []string
+	values := make([][]any, rv.Len())
+	for i := range values {
+		row := rv.Index(i)
+		if row.Kind() == reflect.Ptr && row.IsNil() {
+			return builder.Set(b, "Err", fmt.Errorf("ValuesStructs row %d is nil", i)).(InsertBuilder)
+		}
+		cols, values[i] = info.setColumns(reflect.Indirect(row), false, false)
+	}
+	if cols == nil {
+		cols, _ = info.setColumns(reflect.New(et).Elem(), false, false)
+	}
+
+	return b.setValues(cols, values)
 }
 
 // Select set Select clause for insert query
@@ -234,6 +555,108 @@ func (b InsertBuilder) Select(sb SelectBuilder) InsertBuilder {
 	return builder.Set(b, "Select", &sb).(InsertBuilder)
 }
 
+// Upsert methods
+
+// OnConflict adds an ON CONFLICT clause to the query, with the given columns
+// (if any) as the conflict target. It must be followed by DoNothing or
+// DoUpdateSet.
+//
+// Ex:
+//
+//	Insert("users").Columns("id", "name").Values(1, "a").
+//		OnConflict("id").DoUpdateSetExcluded("name")
+//	// INSERT INTO users (id,name) VALUES (?,?)
+//	// ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name
+func (b InsertBuilder) OnConflict(columns ...string) InsertBuilder {
+	b = builder.Set(b, "OnConflict", true).(InsertBuilder)
+	b = builder.Delete(b, "ConflictConstraint").(InsertBuilder)
+	return builder.Set(b, "ConflictColumns", columns).(InsertBuilder)
+}
+
+// OnConstraint adds an ON CONFLICT ON CONSTRAINT clause to the query. It must
+// be followed by DoNothing or DoUpdateSet.
+func (b InsertBuilder) OnConstraint(name string) InsertBuilder {
+	b = builder.Set(b, "OnConflict", true).(InsertBuilder)
+	b = builder.Delete(b, "ConflictColumns").(InsertBuilder)
+	return builder.Set(b, "ConflictConstraint", name).(InsertBuilder)
+}
+
+// OnConflictWhere adds an expression to the WHERE clause of the conflict
+// target, used to infer a partial unique index. See Where.
+func (b InsertBuilder) OnConflictWhere(pred any, args ...any) InsertBuilder {
+	if pred == nil || pred == "" {
+		return b
+	}
+	return builder.Append(b, "ConflictWhereParts", newWherePart(pred, args...)).(InsertBuilder)
+}
+
+// DoNothing sets the ON CONFLICT action to DO NOTHING, replacing any
+// DoUpdateSet clauses.
+func (b InsertBuilder) DoNothing() InsertBuilder {
+	b = builder.Delete(b, "ConflictSetClauses").(InsertBuilder)
+	b = builder.Delete(b, "ConflictUpdateWhereParts").(InsertBuilder)
+	return builder.Set(b, "ConflictDoNothing", true).(InsertBuilder)
+}
+
+// DoUpdateSet adds a SET clause to the ON CONFLICT DO UPDATE action.
+func (b InsertBuilder) DoUpdateSet(column string, value any) InsertBuilder {
+	b = builder.Delete(b, "ConflictDoNothing").(InsertBuilder)
+	return builder.Append(b, "ConflictSetClauses", setClause{column: column, value: value}).(InsertBuilder)
+}
+
+// DoUpdateSetMap is a convenience method which calls .DoUpdateSet for each
+// key/value pair in clauses.
+func (b InsertBuilder) DoUpdateSetMap(clauses map[string]any) InsertBuilder {
+	for _, key := range getSortedKeys(clauses) {
+		b = b.DoUpdateSet(key, clauses[key])
+	}
+	return b
+}
+
+// DoUpdateSetExcluded is a convenience method which calls .DoUpdateSet to set
+// each column to the value which was proposed for insertion
+// ("column = EXCLUDED.column").
+func (b InsertBuilder) DoUpdateSetExcluded(columns ...string) InsertBuilder {
+	for _, col := range columns {
+		b = b.DoUpdateSet(col, Expr("EXCLUDED."+col))
+	}
+	return b
+}
+
+// DoUpdateWhere adds an expression to the WHERE clause of the ON CONFLICT DO
+// UPDATE action, limiting the rows which are updated. See Where.
+func (b InsertBuilder) DoUpdateWhere(pred any, args ...any) InsertBuilder {
+	if pred == nil || pred == "" {
+		return b
+	}
+	return builder.Append(b, "ConflictUpdateWhereParts", newWherePart(pred, args...)).(InsertBuilder)
+}
+
+// OnDuplicateKeyUpdate adds a MySQL ON DUPLICATE KEY UPDATE clause to the
+// query, with a SET clause for each key/value pair in clauses.
+//
+// Ex:
+//
+//	Insert("users").Columns("id", "name").Values(1, "a").
+//		OnDuplicateKeyUpdate(map[string]any{"name": Expr("VALUES(name)")})
+//	// INSERT INTO users (id,name) VALUES (?,?)
+//	// ON DUPLICATE KEY UPDATE name = VALUES(name)
+func (b InsertBuilder) OnDuplicateKeyUpdate(clauses map[string]any) InsertBuilder {
+	return builder.Extend(b, "DuplicateKeySetClauses", sortedSetClauses(clauses)).(InsertBuilder)
+}
+
+// Returning adds columns to the RETURNING clause of the query, or for SQL
+// Server the OUTPUT clause, where they are qualified with INSERTED.
+func (b InsertBuilder) Returning(columns ...string) InsertBuilder {
+	return builder.Extend(b, "Returning", returningColumns(columns)).(InsertBuilder)
+}
+
+// ReturningExpr adds an expression to the RETURNING (or OUTPUT) clause of the
+// query.
+func (b InsertBuilder) ReturningExpr(expr Sqlizer) InsertBuilder {
+	return builder.Append(b, "Returning", returningPart{expr: expr}).(InsertBuilder)
+}
+
 func (b InsertBuilder) statementKeyword(keyword string) InsertBuilder {
 	return builder.Set(b, "StatementKeyword", keyword).(InsertBuilder)
 }
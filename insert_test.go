@@ -84,3 +84,140 @@ func TestInsertBuilderReplace(t *testing.T) {
 
 	require.Equal(t, expectedSQL, sql)
 }
+
+func TestInsertBuilderSetStruct(t *testing.T) {
+	b := Insert("users").SetStruct(structTestUser{ID: 1, Email: "a@example.com"})
+
+	sql, args, err := b.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO users (id,email) VALUES (?,?)", sql)
+	require.Equal(t, []any{int64(1), "a@example.com"}, args)
+
+	sql, args, err = b.SetStruct(&structTestUser{ID: 2, Nickname: "b"}).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO users (id,email,nickname) VALUES (?,?,?)", sql)
+	require.Equal(t, []any{int64(2), "", "b"}, args)
+}
+
+func TestInsertBuilderValuesStructs(t *testing.T) {
+	b := Insert("users").ValuesStructs([]*structTestUser{
+		{ID: 1, Email: "a@example.com"},
+		{ID: 2, Email: "b@example.com", Nickname: "b"},
+	})
+
+	sql, args, err := b.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO users (id,email,nickname) VALUES (?,?,?),(?,?,?)", sql)
+	require.Equal(t, []any{int64(1), "a@example.com", "", int64(2), "b@example.com", "b"}, args)
+
+	require.Panics(t, func() { Insert("users").ValuesStructs(structTestUser{}) })
+	require.Panics(t, func() { Insert("users").ValuesStructs([]int{1}) })
+
+	_, _, err = Insert("users").ValuesStructs([]*structTestUser{{ID: 1}, nil}).ToSql()
+	require.EqualError(t, err, "ValuesStructs row 1 is nil")
+
+	// replacing the values clears the error
+	b = Insert("users").ValuesStructs([]*structTestUser{nil})
+	sql, args, err = b.ValuesStructs([]*structTestUser{{ID: 1, Email: "a@example.com"}}).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO users (id,email,nickname) VALUES (?,?,?)", sql)
+	require.Equal(t, []any{int64(1), "a@example.com", ""}, args)
+
+	sql, _, err = b.SetMap(map[string]any{"id": 1}).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO users (id) VALUES (?)", sql)
+}
+
+func TestInsertBuilderOnConflict(t *testing.T) {
+	b := Insert("users").
+		Columns("id", "name", "email").
+		Values(1, "a", "a@example.com").
+		OnConflict("id").
+		DoUpdateSetExcluded("name", "email").
+		DoUpdateSet("updated_at", Expr("now()")).
+		DoUpdateWhere("users.locked = ?", false).
+		Suffix("RETURNING id").
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	require.NoError(t, err)
+	require.Equal(t,
+		"INSERT INTO users (id,name,email) VALUES ($1,$2,$3) "+
+			"ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, email = EXCLUDED.email, updated_at = now() "+
+			"WHERE users.locked = $4 RETURNING id",
+		sql)
+	require.Equal(t, []any{1, "a", "a@example.com", false}, args)
+
+	sql, _, err = b.DoNothing().ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO users (id,name,email) VALUES ($1,$2,$3) ON CONFLICT (id) DO NOTHING RETURNING id", sql)
+}
+
+func TestInsertBuilderOnConflictVariants(t *testing.T) {
+	b := Insert("users").Columns("id", "name").Values(1, "a")
+
+	sql, args, err := b.OnConflict().DoNothing().ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO users (id,name) VALUES (?,?) ON CONFLICT DO NOTHING", sql)
+	require.Equal(t, []any{1, "a"}, args)
+
+	sql, args, err = b.OnConstraint("users_pkey").DoUpdateSetMap(map[string]any{"name": "b"}).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO users (id,name) VALUES (?,?) ON CONFLICT ON CONSTRAINT users_pkey DO UPDATE SET name = ?", sql)
+	require.Equal(t, []any{1, "a", "b"}, args)
+
+	sql, args, err = b.OnConflict("email").OnConflictWhere(Eq{"deleted": false}).DoNothing().ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO users (id,name) VALUES (?,?) ON CONFLICT (email) WHERE deleted = ? DO NOTHING", sql)
+	require.Equal(t, []any{1, "a", false}, args)
+
+	sql, _, err = Insert("users").Columns("id").Select(Select("id").From("old")).
+		OnConflict("id").DoNothing().ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO users (id) SELECT id FROM old ON CONFLICT (id) DO NOTHING", sql)
+}
+
+func TestInsertBuilderOnConflictErrors(t *testing.T) {
+	b := Insert("users").Columns("id").Values(1)
+
+	_, _, err := b.OnConflict("id").ToSql()
+	require.EqualError(t, err, "OnConflict requires DoNothing or DoUpdateSet")
+
+	_, _, err = b.OnConflict().DoUpdateSet("id", 2).ToSql()
+	require.EqualError(t, err, "DoUpdateSet requires conflict columns or a constraint")
+
+	_, _, err = b.DoNothing().ToSql()
+	require.EqualError(t, err, "DoUpdateSet and DoNothing require OnConflict or OnConstraint")
+
+	_, _, err = b.OnConflictWhere("deleted_at IS NULL").ToSql()
+	require.EqualError(t, err, "OnConflictWhere and DoUpdateWhere require OnConflict or OnConstraint")
+
+	_, _, err = b.OnConstraint("users_pkey").OnConflictWhere("deleted_at IS NULL").DoNothing().ToSql()
+	require.EqualError(t, err, "OnConflictWhere requires conflict columns")
+
+	_, _, err = b.OnConflict("id").DoNothing().Dialect(MySQL).ToSql()
+	require.EqualError(t, err, "ON CONFLICT is not supported by MySQL")
+
+	_, _, err = b.OnConstraint("pk").DoNothing().Dialect(SQLite).ToSql()
+	require.EqualError(t, err, "ON CONFLICT ON CONSTRAINT is not supported by SQLite")
+
+	_, _, err = b.OnDuplicateKeyUpdate(map[string]any{"id": 2}).Dialect(Postgres).ToSql()
+	require.EqualError(t, err, "ON DUPLICATE KEY UPDATE is not supported by PostgreSQL")
+}
+
+func TestInsertBuilderOnDuplicateKeyUpdate(t *testing.T) {
+	sql, args, err := Insert("users").
+		Columns("id", "name", "visits").
+		Values(1, "a", 1).
+		OnDuplicateKeyUpdate(map[string]any{
+			"visits": Expr("visits + 1"),
+			"name":   Expr("VALUES(name)"),
+		}).
+		Dialect(MySQL).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t,
+		"INSERT INTO users (id,name,visits) VALUES (?,?,?) ON DUPLICATE KEY UPDATE name = VALUES(name), visits = visits + 1",
+		sql)
+	require.Equal(t, []any{1, "a", 1}, args)
+}
@@ -0,0 +1,225 @@
+package sq
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonKeyExpr is a PostgreSQL JSONB key existence test.
+type jsonKeyExpr struct {
+	col  Sqlizer
+	keys []string
+	op   string // ?, ?| or ?&
+	fn   string // equivalent function, for use with Question placeholders
+}
+
+// JsonHasKey builds a PostgreSQL "col ? key" expression, testing whether key
+// exists as a top-level key of the JSONB value col.
+//
+// The ? operator is escaped for the PlaceholderFormat of the statement it is
+// used in, or rendered as the equivalent jsonb_exists function call for the
+// Question format, where it cannot be told apart from a placeholder.
+//
+// col may be a string or a Sqlizer such as JsonPath.
+//
+// Ex:
+//
+//	Select("id").From("t").Where(JsonHasKey("data", "tags")).PlaceholderFormat(Dollar)
+//	// SELECT id FROM t WHERE data ? $1
+func JsonHasKey(col any, key string) Sqlizer {
+	return jsonKeyExpr{col: newPart(col), keys: []string{key}, op: "?", fn: "jsonb_exists"}
+}
+
+// JsonHasAnyKey builds a PostgreSQL "col ?| array[keys]" expression, testing
+// whether any of keys exist as a top-level key of the JSONB value col.
+//
+// See JsonHasKey.
+func JsonHasAnyKey(col any, keys ...string) Sqlizer {
+	return jsonKeyExpr{col: newPart(col), keys: keys, op: "?|", fn: "jsonb_exists_any"}
+}
+
+// JsonHasAllKeys builds a PostgreSQL "col ?& array[keys]" expression, testing
+// whether all of keys exist as top-level keys of the JSONB value col.
+//
+// See JsonHasKey.
+func JsonHasAllKeys(col any, keys ...string) Sqlizer {
+	return jsonKeyExpr{col: newPart(col), keys: keys, op: "?&", fn: "jsonb_exists_all"}
+}
+
+func (e jsonKeyExpr) ToSql() (string, []any, error) {
+	return e.toSqlContext(renderContext{})
+}
+
+func (e jsonKeyExpr) toSqlContext(rc renderContext) (sql string, args []any, err error) {
+	if err = rc.require(FeatureJSONB); err != nil {
+		return
+	}
+	if len(e.keys) == 0 {
+		err = fmt.Errorf("%s requires at least one key", e.op)
+		return
+	}
+
+	colSql, args, err := nestedToSql(rc, e.col)
+	if err != nil {
+		return
+	}
+
+	keysSql := "?"
+	if e.op != "?" {
+		keysSql = "array[" + Placeholders(len(e.keys)) + "]"
+	}
+	for _, key := range e.keys {
+		args = append(args, key)
+	}
+
+	if rc.format == Question {
+		sql = fmt.Sprintf("%s(%s, %s)", e.fn, colSql, keysSql)
+	} else {
+		// ?? is replaced with ? by ReplacePlaceholders.
+		sql = fmt.Sprintf("%s ?%s %s", colSql, e.op, keysSql)
+	}
+	return
+}
+
+// jsonContainsExpr is a PostgreSQL JSONB containment test.
+type jsonContainsExpr struct {
+	col   Sqlizer
+	value any
+}
+
+// JsonContains builds a PostgreSQL "col @> value" expression, testing whether
+// the JSONB value col contains value.
+//
+// value is passed as is if it is a string, []byte, driver.Valuer or Sqlizer,
+// and is otherwise encoded with encoding/json.
+//
+// Ex:
+//
+//	Select("id").From("t").Where(JsonContains("data", map[string]any{"a": 1}))
+//	// SELECT id FROM t WHERE data @> ? [{"a":1}]
+func JsonContains(col any, value any) Sqlizer {
+	return jsonContainsExpr{col: newPart(col), value: value}
+}
+
+func (e jsonContainsExpr) ToSql() (string, []any, error) {
+	return e.toSqlContext(renderContext{})
+}
+
+func (e jsonContainsExpr) toSqlContext(rc renderContext) (sql string, args []any, err error) {
+	if err = rc.require(FeatureJSONB); err != nil {
+		return
+	}
+
+	colSql, args, err := nestedToSql(rc, e.col)
+	if err != nil {
+		return
+	}
+
+	switch v := e.value.(type) {
+	case Sqlizer:
+		vSql, vArgs, err := nestedToSql(rc, v)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s @> %s", colSql, vSql), append(args, vArgs...), nil
+	case string, []byte, driver.Valuer:
+		args = append(args, v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, string(b))
+	}
+
+	sql = colSql + " @> ?"
+	return
+}
+
+// JsonPathExpr is a PostgreSQL JSON path lookup built by JsonPath.
+type JsonPathExpr struct {
+	col  Sqlizer
+	path []any
+	text bool
+}
+
+// JsonPath builds a PostgreSQL expression selecting the element of the JSON
+// value col at the given path of object keys (strings) and array indexes
+// (ints). A single step is rendered with the -> operator, with an array index
+// as a literal, and longer paths with the #> operator.
+//
+// Ex:
+//
+//	JsonPath("data", "tags")            // data -> ?
+//	JsonPath("data", 0)                 // data -> 0
+//	JsonPath("data", "tags", 0)         // data #> array[?,?]
+//	JsonPath("data", "name").Text()     // data ->> ?
+func JsonPath(col any, path ...any) JsonPathExpr {
+	return JsonPathExpr{col: newPart(col), path: path}
+}
+
+// Text returns a copy of the expression which selects the element as text,
+// using the ->> or #>> operators.
+func (e JsonPathExpr) Text() JsonPathExpr {
+	e.text = true
+	return e
+}
+
+func (e JsonPathExpr) ToSql() (string, []any, error) {
+	return e.toSqlContext(renderContext{})
+}
+
+func (e JsonPathExpr) toSqlContext(rc renderContext) (sql string, args []any, err error) {
+	if err = rc.require(FeatureJSONB); err != nil {
+		return
+	}
+	if len(e.path) == 0 {
+		err = fmt.Errorf("json path must have at least one element")
+		return
+	}
+
+	colSql, args, err := nestedToSql(rc, e.col)
+	if err != nil {
+		return
+	}
+
+	if len(e.path) == 1 {
+		op := "->"
+		if e.text {
+			op = "->>"
+		}
+		switch p := e.path[0].(type) {
+		case string:
+			return fmt.Sprintf("%s %s ?", colSql, op), append(args, p), nil
+		case int:
+			// An untyped parameter would be taken as an object key.
+			return fmt.Sprintf("%s %s %d", colSql, op, p), args, nil
+		default:
+			err = fmt.Errorf("json path elements must be strings or ints, not %T", e.path[0])
+			return
+		}
+	}
+
+	op := "#>"
+	if e.text {
+		op = "#>>"
+	}
+	elems := make([]string, len(e.path))
+	for i, p := range e.path {
+		switch p := p.(type) {
+		case string:
+			args = append(args, p)
+		case int:
+			args = append(args, strconv.Itoa(p))
+		default:
+			err = fmt.Errorf("json path elements must be strings or ints, not %T", p)
+			return
+		}
+		elems[i] = "?"
+	}
+	sql = fmt.Sprintf("%s %s array[%s]", colSql, op, strings.Join(elems, ","))
+	return
+}
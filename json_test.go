@@ -0,0 +1,108 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJsonHasKey(t *testing.T) {
+	b := Select("id").From("t").Where(JsonHasKey("data", "tags"))
+
+	sql, args, err := b.PlaceholderFormat(Dollar).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT id FROM t WHERE data ? $1", sql)
+	require.Equal(t, []any{"tags"}, args)
+
+	sql, _, err = b.PlaceholderFormat(AtP).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT id FROM t WHERE data ? @p1", sql)
+
+	sql, args, err = b.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT id FROM t WHERE jsonb_exists(data, ?)", sql)
+	require.Equal(t, []any{"tags"}, args)
+}
+
+func TestJsonHasAnyAllKeys(t *testing.T) {
+	sql, args, err := Select("id").From("t").
+		Where(JsonHasAnyKey("data", "a", "b")).
+		Where(JsonHasAllKeys("data", "c")).
+		PlaceholderFormat(Dollar).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT id FROM t WHERE data ?| array[$1,$2] AND data ?& array[$3]", sql)
+	require.Equal(t, []any{"a", "b", "c"}, args)
+
+	sql, _, err = Select("id").From("t").
+		Where(JsonHasAnyKey("data", "a", "b")).
+		Where(JsonHasAllKeys("data", "c")).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t,
+		"SELECT id FROM t WHERE jsonb_exists_any(data, array[?,?]) AND jsonb_exists_all(data, array[?])", sql)
+
+	_, _, err = JsonHasAnyKey("data").ToSql()
+	require.EqualError(t, err, "?| requires at least one key")
+}
+
+func TestJsonHasKeyDebug(t *testing.T) {
+	b := Select("id").From("t").Where(JsonHasKey("data", "tags")).PlaceholderFormat(Dollar)
+	require.Equal(t, "SELECT id FROM t WHERE data ? 'tags'", Debug(b))
+}
+
+func TestJsonContains(t *testing.T) {
+	sql, args, err := Select("id").From("t").
+		Where(JsonContains("data", map[string]any{"a": 1})).
+		Where(JsonContains("data", `{"b":2}`)).
+		PlaceholderFormat(Dollar).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT id FROM t WHERE data @> $1 AND data @> $2", sql)
+	require.Equal(t, []any{`{"a":1}`, `{"b":2}`}, args)
+
+	sql, args, err = JsonContains("data", Expr("jsonb_build_object('a', ?)", 1)).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "data @> jsonb_build_object('a', ?)", sql)
+	require.Equal(t, []any{1}, args)
+
+	_, _, err = JsonContains("data", func() {}).ToSql()
+	require.Error(t, err)
+}
+
+func TestJsonPath(t *testing.T) {
+	sql, args, err := Select().
+		Column(JsonPath("data", "name").Text()).
+		Column(JsonPath("data", "tags", 0)).
+		From("t").
+		Where(JsonHasKey(JsonPath("data", "meta"), "x")).
+		PlaceholderFormat(Dollar).ToSql()
+	require.NoError(t, err)
+	require.Equal(t,
+		"SELECT data ->> $1, data #> array[$2,$3] FROM t WHERE data -> $4 ? $5", sql)
+	require.Equal(t, []any{"name", "tags", "0", "meta", "x"}, args)
+
+	sql, args, err = JsonPath("data", "a", "b").Text().ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "data #>> array[?,?]", sql)
+	require.Equal(t, []any{"a", "b"}, args)
+
+	// An index is a literal, as a parameter would be an object key.
+	sql, args, err = JsonPath("data", 0).Text().ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "data ->> 0", sql)
+	require.Empty(t, args)
+
+	_, _, err = JsonPath("data").ToSql()
+	require.Error(t, err)
+
+	_, _, err = JsonPath("data", 1.5).ToSql()
+	require.EqualError(t, err, "json path elements must be strings or ints, not float64")
+}
+
+func TestJsonDialect(t *testing.T) {
+	_, _, err := Select("id").From("t").Where(JsonHasKey("data", "a")).Dialect(MySQL).ToSql()
+	require.EqualError(t, err, "JSONB is not supported by MySQL")
+
+	sql, _, err := Select("id").From("t").Where(JsonHasKey("data", "a")).Dialect(Postgres).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT id FROM t WHERE data ? $1", sql)
+}
@@ -0,0 +1,249 @@
+package sq
+
+import "strings"
+
+// sqlToken is the kind of a token produced by lexSql.
+type sqlToken int
+
+const (
+	// sqlText is a run of SQL text, which may include string literals, quoted
+	// identifiers and comments.
+	sqlText sqlToken = iota
+
+	// sqlPlaceholder is a ? placeholder.
+	sqlPlaceholder
+
+	// sqlEscape is a ?? escape, which stands for a literal ?.
+	sqlEscape
+
+	// sqlNamed is a :name or @name parameter, only produced by lexNamedSql.
+	sqlNamed
+)
+
+// lexSql splits sql into text, placeholder and escape tokens, calling fn with
+// each token in order. Concatenating the text of every token gives back sql.
+//
+// A ? is only a placeholder outside of string literals ('...' and E'...'),
+// quoted identifiers ("..." and `...`), dollar-quoted strings ($$...$$ and
+// $tag$...$tag$) and comments (-- ... and /* ... */). For compatibility with
+// SQL written before placeholders were lexed, ?? is an escape everywhere.
+//
+// If backslash is set, a backslash escapes the next character of any string
+// literal, as in MySQL, and not only of E'...' literals.
+func lexSql(sql string, backslash bool, fn func(tok sqlToken, text string)) {
+	l := sqlLexer{sql: sql, fn: fn, backslash: backslash}
+	l.lex()
+}
+
+// lexNamedSql is like lexSql, but also produces sqlNamed tokens for :name and
+// @name parameters. PostgreSQL :: casts and MySQL @@ variables are left as
+// text.
+func lexNamedSql(sql string, backslash bool, fn func(tok sqlToken, text string)) {
+	l := sqlLexer{sql: sql, fn: fn, backslash: backslash, named: true}
+	l.lex()
+}
+
+type sqlLexer struct {
+	sql       string
+	fn        func(tok sqlToken, text string)
+	backslash bool
+	named     bool
+	start     int // start of the pending text token
+	pos       int
+}
+
+func (l *sqlLexer) lex() {
+	for l.pos < len(l.sql) {
+		c := l.sql[l.pos]
+		switch {
+		case c == '?':
+			if l.peek(1) == '?' {
+				l.emit(sqlEscape, 2)
+			} else {
+				l.emit(sqlPlaceholder, 1)
+			}
+		case c == '\'':
+			backslash := l.backslash || l.pos > 0 && (l.sql[l.pos-1] == 'E' || l.sql[l.pos-1] == 'e') &&
+				(l.pos < 2 || !isIdentByte(l.sql[l.pos-2]))
+			l.pos++
+			l.quoted('\'', backslash)
+		case c == '"' && l.backslash:
+			// a MySQL string literal
+			l.pos++
+			l.quoted(c, true)
+		case c == '"' || c == '`':
+			l.pos++
+			l.quoted(c, false)
+		case c == '-' && l.peek(1) == '-':
+			l.pos += 2
+			l.lineComment()
+		case c == '/' && l.peek(1) == '*':
+			l.pos += 2
+			l.blockComment()
+		case l.named && (c == ':' || c == '@'):
+			if l.peek(1) == c {
+				// :: cast or @@ variable
+				l.pos += 2
+			} else if n := l.namedParam(); n > 0 {
+				l.emit(sqlNamed, n)
+			} else {
+				l.pos++
+			}
+		case c == '$' && (l.pos == 0 || !isIdentByte(l.sql[l.pos-1])):
+			if tag, ok := l.dollarTag(); ok {
+				l.pos += len(tag)
+				l.dollarQuoted(tag)
+			} else {
+				l.pos++
+			}
+		default:
+			l.pos++
+		}
+	}
+
+	if l.pos > l.start {
+		l.fn(sqlText, l.sql[l.start:l.pos])
+	}
+}
+
+func (l *sqlLexer) peek(n int) byte {
+	if l.pos+n < len(l.sql) {
+		return l.sql[l.pos+n]
+	}
+	return 0
+}
+
+// emit emits the pending text followed by a token of length n at pos.
+func (l *sqlLexer) emit(tok sqlToken, n int) {
+	if l.pos > l.start {
+		l.fn(sqlText, l.sql[l.start:l.pos])
+	}
+	l.fn(tok, l.sql[l.pos:l.pos+n])
+	l.pos += n
+	l.start = l.pos
+}
+
+// escape emits a ?? escape at pos, returning false if there isn't one.
+func (l *sqlLexer) escape() bool {
+	if l.sql[l.pos] == '?' && l.peek(1) == '?' {
+		l.emit(sqlEscape, 2)
+		return true
+	}
+	return false
+}
+
+// quoted skips past the closing quote of a literal or identifier, where a
+// doubled quote stands for a single one.
+func (l *sqlLexer) quoted(quote byte, backslash bool) {
+	for l.pos < len(l.sql) {
+		if l.escape() {
+			continue
+		}
+		c := l.sql[l.pos]
+		switch {
+		case backslash && c == '\\':
+			l.pos += 2
+		case c == quote && l.peek(1) == quote:
+			l.pos += 2
+		case c == quote:
+			l.pos++
+			return
+		default:
+			l.pos++
+		}
+	}
+	l.pos = len(l.sql)
+}
+
+func (l *sqlLexer) lineComment() {
+	for l.pos < len(l.sql) {
+		if l.escape() {
+			continue
+		}
+		l.pos++
+		if l.sql[l.pos-1] == '\n' {
+			return
+		}
+	}
+}
+
+// blockComment skips past the end of a (possibly nested) block comment.
+func (l *sqlLexer) blockComment() {
+	depth := 1
+	for l.pos < len(l.sql) {
+		if l.escape() {
+			continue
+		}
+		switch {
+		case l.sql[l.pos] == '/' && l.peek(1) == '*':
+			depth++
+			l.pos += 2
+		case l.sql[l.pos] == '*' && l.peek(1) == '/':
+			depth--
+			l.pos += 2
+			if depth == 0 {
+				return
+			}
+		default:
+			l.pos++
+		}
+	}
+}
+
+// namedParam returns the length of the :name or @name parameter at pos, or 0
+// if there isn't one.
+func (l *sqlLexer) namedParam() int {
+	if l.pos > 0 && isIdentByte(l.sql[l.pos-1]) {
+		// e.g. an array slice a[lo:hi]
+		return 0
+	}
+	n := 1
+	for l.pos+n < len(l.sql) && isIdentByte(l.sql[l.pos+n]) && l.sql[l.pos+n] != '$' {
+		c := l.sql[l.pos+n]
+		if n == 1 && c >= '0' && c <= '9' {
+			return 0
+		}
+		n++
+	}
+	if n == 1 {
+		return 0
+	}
+	return n
+}
+
+// dollarTag returns the $tag$ starting at pos, if any.
+func (l *sqlLexer) dollarTag() (string, bool) {
+	for i := l.pos + 1; i < len(l.sql); i++ {
+		c := l.sql[i]
+		switch {
+		case c == '$':
+			return l.sql[l.pos : i+1], true
+		case c >= '0' && c <= '9':
+			if i == l.pos+1 {
+				// $1 is a positional parameter.
+				return "", false
+			}
+		case !isIdentByte(c):
+			return "", false
+		}
+	}
+	return "", false
+}
+
+func (l *sqlLexer) dollarQuoted(tag string) {
+	for l.pos < len(l.sql) {
+		if l.escape() {
+			continue
+		}
+		if strings.HasPrefix(l.sql[l.pos:], tag) {
+			l.pos += len(tag)
+			return
+		}
+		l.pos++
+	}
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
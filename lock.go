@@ -0,0 +1,100 @@
+package sq
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LockStrength is the strength of a row locking clause set by
+// SelectBuilder.For.
+type LockStrength string
+
+const (
+	// LockUpdate is FOR UPDATE.
+	LockUpdate LockStrength = "UPDATE"
+
+	// LockNoKeyUpdate is the PostgreSQL FOR NO KEY UPDATE.
+	LockNoKeyUpdate LockStrength = "NO KEY UPDATE"
+
+	// LockShare is FOR SHARE, which SQL Server has no table hint for.
+	LockShare LockStrength = "SHARE"
+
+	// LockKeyShare is the PostgreSQL FOR KEY SHARE.
+	LockKeyShare LockStrength = "KEY SHARE"
+)
+
+const (
+	lockNoWait     = "NOWAIT"
+	lockSkipLocked = "SKIP LOCKED"
+)
+
+// lockClause returns the table hint to add after the FROM table and the
+// clause to add after LIMIT and OFFSET to lock the rows selected. limited is
+// whether the query has a LIMIT or OFFSET.
+func (rc renderContext) lockClause(strength LockStrength, of []string, wait string, limited bool) (hint, tail string, err error) {
+	if len(strength) == 0 {
+		if len(of) > 0 || len(wait) > 0 {
+			err = fmt.Errorf("OF, NOWAIT and SKIP LOCKED require FOR")
+		}
+		return
+	}
+
+	// Like LIMIT, the locking clause is implied by the SQL Server and Oracle
+	// placeholder formats.
+	d := rc.paginationDialect()
+	switch {
+	case d == nil || d.Supports(FeatureLockFor):
+		switch strength {
+		case LockShare:
+			if d != nil && !d.Supports(FeatureLockShare) {
+				err = fmt.Errorf("%s is not supported by %s", FeatureLockShare, d.Name())
+				return
+			}
+		case LockNoKeyUpdate, LockKeyShare:
+			if d != nil && !d.Supports(FeatureLockKeyStrength) {
+				err = fmt.Errorf("FOR %s is not supported by %s", strength, d.Name())
+				return
+			}
+		}
+
+		if d != nil && limited && !d.Supports(FeatureLimitOffset) {
+			// Oracle rejects FOR UPDATE with OFFSET ... FETCH (ORA-02014).
+			err = fmt.Errorf("%s with LIMIT or OFFSET is not supported by %s", FeatureLockFor, d.Name())
+			return
+		}
+
+		tail = " FOR " + string(strength)
+		if len(of) > 0 {
+			tail += " OF " + strings.Join(of, ", ")
+		}
+		if len(wait) > 0 {
+			tail += " " + wait
+		}
+	case d.Supports(FeatureLockHints):
+		if len(of) > 0 {
+			err = fmt.Errorf("FOR ... OF is not supported by %s", d.Name())
+			return
+		}
+
+		hints := []string{}
+		switch strength {
+		case LockUpdate:
+			hints = append(hints, "UPDLOCK")
+		default:
+			// HOLDLOCK would be serializable range locking rather than
+			// FOR SHARE.
+			err = fmt.Errorf("FOR %s is not supported by %s", strength, d.Name())
+			return
+		}
+		switch wait {
+		case lockSkipLocked:
+			hints = append(hints, "READPAST")
+		case lockNoWait:
+			hints = append(hints, "NOWAIT")
+		}
+		hint = " WITH (" + strings.Join(hints, ", ") + ")"
+	default:
+		err = fmt.Errorf("%s is not supported by %s", FeatureLockFor, d.Name())
+	}
+	return
+}
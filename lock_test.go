@@ -0,0 +1,111 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectBuilderFor(t *testing.T) {
+	b := Select("id").From("jobs j").Where(Eq{"state": "queued"}).OrderBy("id").Limit(1)
+
+	tests := []struct {
+		name string
+		b    SelectBuilder
+		sql  string
+		err  string
+	}{
+		{
+			name: "update skip locked",
+			b:    b.For(LockUpdate).SkipLocked().Suffix("-- x"),
+			sql:  "SELECT id FROM jobs j WHERE state = ? ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED -- x",
+		},
+		{
+			name: "no key update of nowait",
+			b:    b.For(LockNoKeyUpdate).Of("j").NoWait().Dialect(Postgres),
+			sql:  "SELECT id FROM jobs j WHERE state = $1 ORDER BY id LIMIT 1 FOR NO KEY UPDATE OF j NOWAIT",
+		},
+		{
+			name: "key share",
+			b:    b.For(LockKeyShare).Offset(2).Dialect(Postgres),
+			sql:  "SELECT id FROM jobs j WHERE state = $1 ORDER BY id LIMIT 1 OFFSET 2 FOR KEY SHARE",
+		},
+		{
+			name: "mysql share",
+			b:    b.For(LockShare).SkipLocked().Dialect(MySQL),
+			sql:  "SELECT id FROM jobs j WHERE state = ? ORDER BY id LIMIT 1 FOR SHARE SKIP LOCKED",
+		},
+		{
+			name: "mysql key share",
+			b:    b.For(LockKeyShare).Dialect(MySQL),
+			err:  "FOR KEY SHARE is not supported by MySQL",
+		},
+		{
+			name: "sql server update skip locked",
+			b:    b.For(LockUpdate).SkipLocked().Dialect(SQLServer),
+			sql:  "SELECT TOP 1 id FROM jobs j WITH (UPDLOCK, READPAST) WHERE state = @p1 ORDER BY id",
+		},
+		{
+			name: "sql server update nowait",
+			b:    b.RemoveLimit().For(LockUpdate).NoWait().Dialect(SQLServer),
+			sql:  "SELECT id FROM jobs j WITH (UPDLOCK, NOWAIT) WHERE state = @p1 ORDER BY id",
+		},
+		{
+			name: "sql server share",
+			b:    b.For(LockShare).Dialect(SQLServer),
+			err:  "FOR SHARE is not supported by SQL Server",
+		},
+		{
+			name: "sql server of",
+			b:    b.For(LockUpdate).Of("j").Dialect(SQLServer),
+			err:  "FOR ... OF is not supported by SQL Server",
+		},
+		{
+			name: "oracle share",
+			b:    b.For(LockShare).Dialect(Oracle),
+			err:  "FOR SHARE is not supported by Oracle",
+		},
+		{
+			name: "oracle limit",
+			b:    b.For(LockUpdate).Dialect(Oracle),
+			err:  "FOR UPDATE with LIMIT or OFFSET is not supported by Oracle",
+		},
+		{
+			name: "oracle",
+			b:    b.RemoveLimit().For(LockUpdate).SkipLocked().Dialect(Oracle),
+			sql:  "SELECT id FROM jobs j WHERE state = :1 ORDER BY id FOR UPDATE SKIP LOCKED",
+		},
+		{
+			name: "sql server placeholder format",
+			b:    b.For(LockUpdate).PlaceholderFormat(AtP),
+			sql:  "SELECT TOP 1 id FROM jobs j WITH (UPDLOCK) WHERE state = @p1 ORDER BY id",
+		},
+		{
+			name: "sqlite",
+			b:    b.For(LockUpdate).Dialect(SQLite),
+			err:  "FOR UPDATE is not supported by SQLite",
+		},
+		{
+			name: "without for",
+			b:    b.SkipLocked(),
+			err:  "OF, NOWAIT and SKIP LOCKED require FOR",
+		},
+		{
+			name: "removed",
+			b:    b.For(LockUpdate).SkipLocked().RemoveFor(),
+			sql:  "SELECT id FROM jobs j WHERE state = ? ORDER BY id LIMIT 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, _, err := tt.b.ToSql()
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.sql, sql)
+		})
+	}
+}
@@ -0,0 +1,551 @@
+package sq
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/userhubdev/sq/internal/builder"
+)
+
+type mergeData struct {
+	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
+	RunWith           Runner
+	Prefixes          []Sqlizer
+	Into              string
+	Using             Sqlizer
+	OnParts           []Sqlizer
+	WhenClauses       []mergeWhen
+	Suffixes          []Sqlizer
+}
+
+// mergeWhen is a WHEN clause of a MERGE statement.
+type mergeWhen struct {
+	match   string // MATCHED, NOT MATCHED or NOT MATCHED BY SOURCE
+	cond    Sqlizer
+	action  string // UPDATE, DELETE or INSERT
+	set     []setClause
+	columns []string
+	values  []any
+}
+
+func (d *mergeData) ExecContext(ctx context.Context) (sql.Result, error) {
+	if d.RunWith == nil {
+		return nil, ErrRunnerNotSet
+	}
+	return ExecContextWith(ctx, d.RunWith, d)
+}
+
+func (d *mergeData) QueryContext(ctx context.Context) (*sql.Rows, error) {
+	if d.RunWith == nil {
+		return nil, ErrRunnerNotSet
+	}
+	return QueryContextWith(ctx, d.RunWith, d)
+}
+
+func (d *mergeData) QueryRowContext(ctx context.Context) RowScanner {
+	if d.RunWith == nil {
+		return &Row{err: ErrRunnerNotSet}
+	}
+	return QueryRowContextWith(ctx, d.RunWith, d)
+}
+
+func (d *mergeData) ToSql() (sqlStr string, args []any, err error) {
+	sqlStr, args, err = d.ToSqlRaw()
+	if err != nil {
+		return
+	}
+
+	sqlStr, args, err = replacePlaceholders(d.PlaceholderFormat, sqlStr, args)
+	return
+}
+
+func (d *mergeData) ToSqlRaw() (sqlStr string, args []any, err error) {
+	return d.toSqlRaw(renderContext{dialect: d.Dialect, format: d.PlaceholderFormat})
+}
+
+func (d *mergeData) toSqlRaw(rc renderContext) (sqlStr string, args []any, err error) {
+	if len(d.Into) == 0 {
+		err = errors.New("merge statements must specify a target table")
+		return
+	}
+	if d.Using == nil {
+		err = errors.New("merge statements must specify a source with Using")
+		return
+	}
+	if len(d.OnParts) == 0 {
+		err = errors.New("merge statements must have an On condition")
+		return
+	}
+	if len(d.WhenClauses) == 0 {
+		err = errors.New("merge statements must have at least one When clause")
+		return
+	}
+	if err = rc.require(FeatureMerge); err != nil {
+		return
+	}
+
+	sql := &strings.Builder{}
+
+	if len(d.Prefixes) > 0 {
+		args, err = appendToSql(rc, d.Prefixes, sql, " ", args)
+		if err != nil {
+			return
+		}
+
+		sql.WriteString(" ")
+	}
+
+	sql.WriteString("MERGE INTO ")
+	sql.WriteString(d.Into)
+
+	sql.WriteString(" USING ")
+	args, err = appendToSql(rc, []Sqlizer{d.Using}, sql, "", args)
+	if err != nil {
+		return
+	}
+
+	// Oracle requires the ON condition to be parenthesized, and has its own
+	// form of WHEN clauses.
+	oracleForm := rc.dialect != nil && !rc.dialect.Supports(FeatureMergeWhenAnd)
+
+	sql.WriteString(" ON ")
+	if oracleForm {
+		sql.WriteString("(")
+	}
+	args, err = appendToSql(rc, d.OnParts, sql, " AND ", args)
+	if err != nil {
+		return
+	}
+	if oracleForm {
+		sql.WriteString(")")
+	}
+
+	if oracleForm {
+		args, err = d.appendOracleWhensToSql(rc, sql, args)
+		if err != nil {
+			return
+		}
+	} else {
+		for _, when := range d.WhenClauses {
+			args, err = when.appendToSql(rc, sql, args)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	if len(d.Suffixes) > 0 {
+		sql.WriteString(" ")
+		args, err = appendToSql(rc, d.Suffixes, sql, " ", args)
+		if err != nil {
+			return
+		}
+	}
+
+	if rc.dialect != nil && rc.dialect.Supports(FeatureMergeTerminator) {
+		sql.WriteString(";")
+	}
+
+	sqlStr = sql.String()
+	return
+}
+
+// appendOracleWhensToSql appends the WHEN clauses in Oracle's form, which
+// has at most one WHEN MATCHED THEN UPDATE clause, optionally followed by
+// DELETE WHERE, and one WHEN NOT MATCHED THEN INSERT clause, with their
+// conditions in WHERE clauses.
+func (d *mergeData) appendOracleWhensToSql(rc renderContext, sql *strings.Builder, args []any) ([]any, error) {
+	name := rc.dialect.Name()
+
+	var update, del, insert *mergeWhen
+	for i := range d.WhenClauses {
+		w := &d.WhenClauses[i]
+		if err := w.check(rc); err != nil {
+			return nil, err
+		}
+
+		switch w.action {
+		case "UPDATE":
+			if update != nil {
+				return nil, fmt.Errorf("%s allows only one WHEN MATCHED THEN UPDATE clause", name)
+			}
+			update = w
+		case "DELETE":
+			if update == nil || del != nil {
+				return nil, fmt.Errorf("WHEN MATCHED THEN DELETE must follow a WHEN MATCHED THEN UPDATE clause on %s", name)
+			}
+			if w.cond == nil {
+				return nil, fmt.Errorf("WHEN MATCHED THEN DELETE requires a condition on %s", name)
+			}
+			del = w
+		case "INSERT":
+			if insert != nil {
+				return nil, fmt.Errorf("%s allows only one WHEN NOT MATCHED clause", name)
+			}
+			insert = w
+		}
+	}
+
+	var err error
+	for _, w := range []*mergeWhen{update, insert} {
+		if w == nil {
+			continue
+		}
+
+		sql.WriteString(" WHEN ")
+		sql.WriteString(w.match)
+		sql.WriteString(" THEN ")
+		if args, err = w.appendActionToSql(rc, sql, args); err != nil {
+			return nil, err
+		}
+
+		if w.cond != nil {
+			sql.WriteString(" WHERE ")
+			if args, err = appendToSql(rc, []Sqlizer{w.cond}, sql, "", args); err != nil {
+				return nil, err
+			}
+		}
+
+		if w == update && del != nil {
+			sql.WriteString(" DELETE WHERE ")
+			if args, err = appendToSql(rc, []Sqlizer{del.cond}, sql, "", args); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return args, nil
+}
+
+// check returns an error if the clause's action doesn't apply to its rows or
+// isn't supported.
+func (w mergeWhen) check(rc renderContext) error {
+	if w.match == "NOT MATCHED BY SOURCE" {
+		if err := rc.require(FeatureMergeBySource); err != nil {
+			return err
+		}
+	}
+
+	if (w.match == "NOT MATCHED") != (w.action == "INSERT") {
+		return fmt.Errorf("WHEN %s clauses cannot %s", w.match, w.action)
+	}
+	return nil
+}
+
+func (w mergeWhen) appendToSql(rc renderContext, sql *strings.Builder, args []any) ([]any, error) {
+	if err := w.check(rc); err != nil {
+		return nil, err
+	}
+
+	sql.WriteString(" WHEN ")
+	sql.WriteString(w.match)
+
+	if w.cond != nil {
+		sql.WriteString(" AND ")
+		var err error
+		args, err = appendToSql(rc, []Sqlizer{w.cond}, sql, "", args)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sql.WriteString(" THEN ")
+	return w.appendActionToSql(rc, sql, args)
+}
+
+// appendActionToSql appends the action of the clause, which follows THEN.
+func (w mergeWhen) appendActionToSql(rc renderContext, sql *strings.Builder, args []any) ([]any, error) {
+	switch w.action {
+	case "UPDATE":
+		if len(w.set) == 0 {
+			return nil, fmt.Errorf("WHEN %s THEN UPDATE must have at least one Set clause", w.match)
+		}
+		sql.WriteString("UPDATE SET ")
+		return appendSetClauses(rc, w.set, sql, args)
+	case "DELETE":
+		sql.WriteString("DELETE")
+	case "INSERT":
+		if len(w.columns) != len(w.values) {
+			return nil, fmt.Errorf("WHEN %s THEN INSERT has %d columns but %d values",
+				w.match, len(w.columns), len(w.values))
+		}
+		sql.WriteString("INSERT ")
+		if len(w.columns) > 0 {
+			sql.WriteString("(")
+			sql.WriteString(strings.Join(w.columns, ", "))
+			sql.WriteString(") ")
+		}
+		sql.WriteString("VALUES (")
+		for i, val := range w.values {
+			if i > 0 {
+				sql.WriteString(", ")
+			}
+			if vs, ok := val.(Sqlizer); ok {
+				vsql, vargs, err := nestedToSql(rc, vs)
+				if err != nil {
+					return nil, err
+				}
+				sql.WriteString(vsql)
+				args = append(args, vargs...)
+			} else {
+				sql.WriteString("?")
+				args = append(args, val)
+			}
+		}
+		sql.WriteString(")")
+	}
+
+	return args, nil
+}
+
+// Builder
+
+// MergeBuilder builds SQL MERGE statements.
+type MergeBuilder builder.Builder
+
+func init() {
+	builder.Register(MergeBuilder{}, mergeData{})
+}
+
+// Format methods
+
+// PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the
+// query.
+func (b MergeBuilder) PlaceholderFormat(f PlaceholderFormat) MergeBuilder {
+	return builder.Set(b, "PlaceholderFormat", f).(MergeBuilder)
+}
+
+// Dialect sets the Dialect (e.g. Postgres or MySQL) for the query, and sets the
+// PlaceholderFormat to the dialect's default. A nil Dialect clears it, keeping
+// the PlaceholderFormat.
+func (b MergeBuilder) Dialect(d Dialect) MergeBuilder {
+	if d == nil {
+		return builder.Delete(b, "Dialect").(MergeBuilder)
+	}
+	b = builder.Set(b, "Dialect", d).(MergeBuilder)
+	return b.PlaceholderFormat(d.PlaceholderFormat())
+}
+
+// Runner methods
+
+// RunWith sets a Runner (like database/sql.DB) to be used with e.g.
+// ExecContext.
+func (b MergeBuilder) RunWith(runner Runner) MergeBuilder {
+	return builder.Set(b, "RunWith", runner).(MergeBuilder)
+}
+
+// ExecContext builds and ExecContexts the query with the Runner set by RunWith.
+func (b MergeBuilder) ExecContext(ctx context.Context) (sql.Result, error) {
+	data := builder.GetStruct(b).(mergeData)
+	return data.ExecContext(ctx)
+}
+
+// QueryContext builds and QueryContexts the query with the Runner set by
+// RunWith.
+func (b MergeBuilder) QueryContext(ctx context.Context) (*sql.Rows, error) {
+	data := builder.GetStruct(b).(mergeData)
+	return data.QueryContext(ctx)
+}
+
+// QueryRowContext builds and QueryRowContexts the query with the Runner set by
+// RunWith.
+func (b MergeBuilder) QueryRowContext(ctx context.Context) RowScanner {
+	data := builder.GetStruct(b).(mergeData)
+	return data.QueryRowContext(ctx)
+}
+
+// SQL methods
+
+// ToSql builds the query into a SQL string and bound args.
+func (b MergeBuilder) ToSql() (string, []any, error) {
+	data := builder.GetStruct(b).(mergeData)
+	return data.ToSql()
+}
+
+// ToSqlRaw builds the query into a SQL string and bound args without
+// replacing the placeholders, for use in nested queries.
+func (b MergeBuilder) ToSqlRaw() (string, []any, error) {
+	data := builder.GetStruct(b).(mergeData)
+	return data.ToSqlRaw()
+}
+
+// toSqlContext builds a nested query, inheriting the Dialect of the enclosing
+// statement if none is set.
+func (b MergeBuilder) toSqlContext(rc renderContext) (string, []any, error) {
+	data := builder.GetStruct(b).(mergeData)
+	if data.Dialect != nil {
+		rc.dialect = data.Dialect
+	}
+	return data.toSqlRaw(rc)
+}
+
+// Prefix adds an expression to the beginning of the query
+func (b MergeBuilder) Prefix(sql string, args ...any) MergeBuilder {
+	return b.PrefixExpr(Expr(sql, args...))
+}
+
+// PrefixExpr adds an expression to the very beginning of the query
+func (b MergeBuilder) PrefixExpr(expr Sqlizer) MergeBuilder {
+	return builder.Append(b, "Prefixes", expr).(MergeBuilder)
+}
+
+// Into sets the target table of the query.
+func (b MergeBuilder) Into(into string) MergeBuilder {
+	return builder.Set(b, "Into", into).(MergeBuilder)
+}
+
+// Using sets the source of the query, which may be a table name or a Sqlizer
+// such as a SelectBuilder, with the given alias (if any). The alias follows
+// AS, except for dialects which don't accept it, such as Oracle.
+//
+// Ex:
+//
+//	Merge("users u").Using(Select("*").From("staged_users"), "s")
+//	// MERGE INTO users u USING (SELECT * FROM staged_users) AS s ...
+func (b MergeBuilder) Using(source any, alias string) MergeBuilder {
+	using := mergeSource{alias: alias}
+	switch s := source.(type) {
+	case string:
+		using.source = newPart(s)
+	case Sqlizer:
+		using.source = s
+		using.subquery = true
+	default:
+		using.source = newPart(source)
+	}
+	return builder.Set(b, "Using", using).(MergeBuilder)
+}
+
+// mergeSource is the USING clause of a MERGE statement.
+type mergeSource struct {
+	source   Sqlizer
+	subquery bool
+	alias    string
+}
+
+func (s mergeSource) ToSql() (string, []any, error) {
+	return s.toSqlContext(renderContext{})
+}
+
+func (s mergeSource) toSqlContext(rc renderContext) (string, []any, error) {
+	sql, args, err := nestedToSql(rc, s.source)
+	if err != nil {
+		return "", nil, err
+	}
+	if s.subquery {
+		sql = "(" + sql + ")"
+	}
+
+	if len(s.alias) > 0 {
+		// Oracle doesn't accept AS before a table alias.
+		if rc.dialect == nil || rc.dialect.Supports(FeatureTableAliasAs) {
+			sql += " AS " + s.alias
+		} else {
+			sql += " " + s.alias
+		}
+	}
+	return sql, args, nil
+}
+
+// On adds an expression to the ON clause of the query, which joins the source
+// to the target. Expressions are ANDed together.
+//
+// See SelectBuilder.Where for the accepted types of pred.
+func (b MergeBuilder) On(pred any, args ...any) MergeBuilder {
+	return builder.Append(b, "OnParts", newWherePart(pred, args...)).(MergeBuilder)
+}
+
+// WhenMatched starts a WHEN MATCHED clause, for rows of the target which
+// match a row of the source. If pred is not nil, the clause only applies to
+// rows for which it is true.
+//
+// The clause is added to the query by calling Update or Delete on the result.
+//
+// Dialects without FeatureMergeWhenAnd, such as Oracle, allow a single Update
+// clause, whose pred becomes a WHERE clause, optionally followed by a Delete
+// clause with a pred, which becomes DELETE WHERE. Note that Oracle only
+// deletes the updated rows, and evaluates pred after the update.
+//
+// Ex:
+//
+//	Merge("users u").Using("staged", "s").On("u.id = s.id").
+//		WhenMatched("s.name <> u.name").Update(map[string]any{"name": Expr("s.name")}).
+//		WhenMatched("s.deleted = 1").Delete().
+//		Dialect(Oracle)
+//	// MERGE INTO users u USING staged s ON (u.id = s.id)
+//	// WHEN MATCHED THEN UPDATE SET name = s.name WHERE s.name <> u.name DELETE WHERE s.deleted = 1
+func (b MergeBuilder) WhenMatched(pred any, args ...any) MergeWhenBuilder {
+	return b.when("MATCHED", pred, args)
+}
+
+// WhenNotMatched starts a WHEN NOT MATCHED clause, for rows of the source
+// which don't match any row of the target. If pred is not nil, the clause
+// only applies to rows for which it is true.
+//
+// The clause is added to the query by calling Insert on the result. Oracle
+// allows a single such clause, whose pred becomes a WHERE clause.
+func (b MergeBuilder) WhenNotMatched(pred any, args ...any) MergeWhenBuilder {
+	return b.when("NOT MATCHED", pred, args)
+}
+
+// WhenNotMatchedBySource starts a WHEN NOT MATCHED BY SOURCE clause, for rows
+// of the target which don't match any row of the source. If pred is not nil,
+// the clause only applies to rows for which it is true.
+//
+// The clause is added to the query by calling Update or Delete on the result.
+func (b MergeBuilder) WhenNotMatchedBySource(pred any, args ...any) MergeWhenBuilder {
+	return b.when("NOT MATCHED BY SOURCE", pred, args)
+}
+
+func (b MergeBuilder) when(match string, pred any, args []any) MergeWhenBuilder {
+	w := mergeWhen{match: match}
+	if pred != nil && pred != "" {
+		w.cond = newWherePart(pred, args...)
+	}
+	return MergeWhenBuilder{b: b, when: w}
+}
+
+// Suffix adds an expression to the end of the query
+func (b MergeBuilder) Suffix(sql string, args ...any) MergeBuilder {
+	return b.SuffixExpr(Expr(sql, args...))
+}
+
+// SuffixExpr adds an expression to the end of the query
+func (b MergeBuilder) SuffixExpr(expr Sqlizer) MergeBuilder {
+	return builder.Append(b, "Suffixes", expr).(MergeBuilder)
+}
+
+// MergeWhenBuilder builds a WHEN clause of a MERGE statement. It is returned
+// by MergeBuilder.WhenMatched, WhenNotMatched and WhenNotMatchedBySource, and
+// its methods add the clause to the MergeBuilder.
+type MergeWhenBuilder struct {
+	b    MergeBuilder
+	when mergeWhen
+}
+
+// Update adds the clause with an UPDATE action, setting the columns to the
+// values in clauses. Values may be Sqlizers, e.g. Expr("s.name").
+func (w MergeWhenBuilder) Update(clauses map[string]any) MergeBuilder {
+	w.when.action = "UPDATE"
+	w.when.set = sortedSetClauses(clauses)
+	return builder.Append(w.b, "WhenClauses", w.when).(MergeBuilder)
+}
+
+// Delete adds the clause with a DELETE action.
+func (w MergeWhenBuilder) Delete() MergeBuilder {
+	w.when.action = "DELETE"
+	return builder.Append(w.b, "WhenClauses", w.when).(MergeBuilder)
+}
+
+// Insert adds the clause with an INSERT action, inserting the values into the
+// columns. Values may be Sqlizers, e.g. Expr("s.name").
+func (w MergeWhenBuilder) Insert(columns []string, values []any) MergeBuilder {
+	w.when.action = "INSERT"
+	w.when.columns = columns
+	w.when.values = values
+	return builder.Append(w.b, "WhenClauses", w.when).(MergeBuilder)
+}
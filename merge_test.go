@@ -0,0 +1,179 @@
+package sq
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeBuilderToSql(t *testing.T) {
+	b := Merge("users u").
+		Prefix("WITH s AS (SELECT * FROM staged WHERE batch = ?)", 7).
+		Using("s", "src").
+		On("u.id = src.id").
+		WhenMatched("src.deleted").Delete().
+		WhenMatched(nil).Update(map[string]any{"name": Expr("src.name"), "synced": true}).
+		WhenNotMatched(Eq{"src.deleted": false}).Insert([]string{"id", "name"}, []any{Expr("src.id"), Expr("src.name")}).
+		WhenNotMatchedBySource(nil).Update(map[string]any{"active": false}).
+		Suffix("RETURNING merge_action()").
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	require.NoError(t, err)
+	require.Equal(t,
+		"WITH s AS (SELECT * FROM staged WHERE batch = $1) "+
+			"MERGE INTO users u USING s AS src ON u.id = src.id "+
+			"WHEN MATCHED AND src.deleted THEN DELETE "+
+			"WHEN MATCHED THEN UPDATE SET name = src.name, synced = $2 "+
+			"WHEN NOT MATCHED AND src.deleted = $3 THEN INSERT (id, name) VALUES (src.id, src.name) "+
+			"WHEN NOT MATCHED BY SOURCE THEN UPDATE SET active = $4 "+
+			"RETURNING merge_action()",
+		sql)
+	require.Equal(t, []any{7, true, false, false}, args)
+}
+
+func TestMergeBuilderUsingSelect(t *testing.T) {
+	src := Select("id", "name").From("staged").Where(Eq{"batch": 7})
+
+	sql, args, err := Merge("users").
+		Using(src, "s").
+		On("users.id = s.id").
+		WhenNotMatched(nil).Insert([]string{"id", "name"}, []any{Expr("s.id"), "x"}).
+		Dialect(SQLServer).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t,
+		"MERGE INTO users USING (SELECT id, name FROM staged WHERE batch = @p1) AS s ON users.id = s.id "+
+			"WHEN NOT MATCHED THEN INSERT (id, name) VALUES (s.id, @p2);",
+		sql)
+	require.Equal(t, []any{7, "x"}, args)
+
+	// wrapped dialects terminate the statement too
+	sql, _, err = Merge("users").Using("staged", "s").On("users.id = s.id").WhenMatched(nil).Delete().
+		Dialect(WithArrayParams(SQLServer, nil)).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "MERGE INTO users USING staged AS s ON users.id = s.id WHEN MATCHED THEN DELETE;", sql)
+
+	// Oracle doesn't accept AS before a table alias.
+	sql, _, err = Merge("users u").
+		Using(src, "s").
+		On("u.id = s.id").
+		WhenMatched(nil).Update(map[string]any{"name": Expr("s.name")}).
+		Dialect(Oracle).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "MERGE INTO users u USING (SELECT id, name FROM staged WHERE batch = :1) s ON (u.id = s.id) WHEN MATCHED THEN UPDATE SET name = s.name", sql)
+}
+
+func TestMergeBuilderOracle(t *testing.T) {
+	b := Merge("users u").Using("staged", "s").On("u.id = s.id").Dialect(Oracle)
+	update := map[string]any{"name": Expr("s.name")}
+	insert := func(b MergeBuilder, pred any, args ...any) MergeBuilder {
+		return b.WhenNotMatched(pred, args...).Insert([]string{"id", "name"}, []any{Expr("s.id"), Expr("s.name")})
+	}
+
+	tests := []struct {
+		name string
+		b    MergeBuilder
+		sql  string
+		args []any
+	}{
+		{
+			name: "update",
+			b:    b.WhenMatched(nil).Update(update),
+			sql:  "MERGE INTO users u USING staged s ON (u.id = s.id) WHEN MATCHED THEN UPDATE SET name = s.name",
+		},
+		{
+			name: "update where",
+			b:    b.WhenMatched(Eq{"s.active": 1}).Update(update),
+			sql:  "MERGE INTO users u USING staged s ON (u.id = s.id) WHEN MATCHED THEN UPDATE SET name = s.name WHERE s.active = :1",
+			args: []any{1},
+		},
+		{
+			name: "update delete where",
+			b:    b.WhenMatched(nil).Update(update).WhenMatched("s.deleted = ?", 1).Delete(),
+			sql:  "MERGE INTO users u USING staged s ON (u.id = s.id) WHEN MATCHED THEN UPDATE SET name = s.name DELETE WHERE s.deleted = :1",
+			args: []any{1},
+		},
+		{
+			name: "insert",
+			b:    insert(b, nil),
+			sql:  "MERGE INTO users u USING staged s ON (u.id = s.id) WHEN NOT MATCHED THEN INSERT (id, name) VALUES (s.id, s.name)",
+		},
+		{
+			name: "insert where",
+			b:    insert(b, "s.active = ?", true),
+			sql:  "MERGE INTO users u USING staged s ON (u.id = s.id) WHEN NOT MATCHED THEN INSERT (id, name) VALUES (s.id, s.name) WHERE s.active = :1",
+			args: []any{true},
+		},
+		{
+			name: "all",
+			b:    insert(b.WhenMatched("s.v > ?", 1).Update(update), nil).WhenMatched("s.deleted = ?", 2).Delete(),
+			sql: "MERGE INTO users u USING staged s ON (u.id = s.id) " +
+				"WHEN MATCHED THEN UPDATE SET name = s.name WHERE s.v > :1 DELETE WHERE s.deleted = :2 " +
+				"WHEN NOT MATCHED THEN INSERT (id, name) VALUES (s.id, s.name)",
+			args: []any{1, 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sql, args, err := test.b.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.sql, sql)
+			require.Equal(t, test.args, args)
+		})
+	}
+
+	_, _, err := b.WhenMatched("s.deleted = 1").Delete().ToSql()
+	require.EqualError(t, err, "WHEN MATCHED THEN DELETE must follow a WHEN MATCHED THEN UPDATE clause on Oracle")
+
+	_, _, err = b.WhenMatched(nil).Update(update).WhenMatched(nil).Delete().ToSql()
+	require.EqualError(t, err, "WHEN MATCHED THEN DELETE requires a condition on Oracle")
+
+	_, _, err = b.WhenMatched("s.v = 1").Update(update).WhenMatched(nil).Update(update).ToSql()
+	require.EqualError(t, err, "Oracle allows only one WHEN MATCHED THEN UPDATE clause")
+
+	_, _, err = insert(insert(b, "s.v = 1"), nil).ToSql()
+	require.EqualError(t, err, "Oracle allows only one WHEN NOT MATCHED clause")
+}
+
+func TestMergeBuilderErrors(t *testing.T) {
+	b := Merge("users").Using("s", "").On("users.id = s.id")
+
+	_, _, err := b.ToSql()
+	require.EqualError(t, err, "merge statements must have at least one When clause")
+
+	_, _, err = Merge("users").WhenMatched(nil).Delete().ToSql()
+	require.EqualError(t, err, "merge statements must specify a source with Using")
+
+	_, _, err = b.WhenNotMatched(nil).Delete().ToSql()
+	require.EqualError(t, err, "WHEN NOT MATCHED clauses cannot DELETE")
+
+	_, _, err = b.WhenMatched(nil).Insert(nil, nil).ToSql()
+	require.EqualError(t, err, "WHEN MATCHED clauses cannot INSERT")
+
+	_, _, err = b.WhenMatched(nil).Update(nil).ToSql()
+	require.EqualError(t, err, "WHEN MATCHED THEN UPDATE must have at least one Set clause")
+
+	_, _, err = b.WhenNotMatched(nil).Insert([]string{"id"}, nil).ToSql()
+	require.EqualError(t, err, "WHEN NOT MATCHED THEN INSERT has 1 columns but 0 values")
+
+	_, _, err = b.WhenMatched(nil).Delete().Dialect(MySQL).ToSql()
+	require.EqualError(t, err, "MERGE is not supported by MySQL")
+
+	_, _, err = b.WhenNotMatchedBySource(nil).Delete().Dialect(Oracle).ToSql()
+	require.EqualError(t, err, "WHEN NOT MATCHED BY SOURCE is not supported by Oracle")
+}
+
+func TestMergeBuilderRunners(t *testing.T) {
+	db, fake := newFakeDB(nil)
+
+	_, err := Merge("users").Using("s", "").On("users.id = s.id").
+		WhenMatched(nil).Delete().
+		RunWith(db).ExecContext(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"MERGE INTO users USING s ON users.id = s.id WHEN MATCHED THEN DELETE"}, fake.queries)
+}
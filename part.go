@@ -11,15 +11,22 @@ type part struct {
 }
 
 func newPart(pred any, args ...any) Sqlizer {
+	if named := namedPart(pred, args); named != nil {
+		return named
+	}
 	return &part{pred, args}
 }
 
 func (p part) ToSql() (sql string, args []any, err error) {
+	return p.toSqlContext(renderContext{})
+}
+
+func (p part) toSqlContext(rc renderContext) (sql string, args []any, err error) {
 	switch pred := p.pred.(type) {
 	case nil:
 		// no-op
 	case Sqlizer:
-		sql, args, err = nestedToSql(pred)
+		sql, args, err = nestedToSql(rc, pred)
 	case string:
 		sql = pred
 		args = p.args
@@ -29,17 +36,34 @@ func (p part) ToSql() (sql string, args []any, err error) {
 	return
 }
 
-func nestedToSql(s Sqlizer) (string, []any, error) {
-	if raw, ok := s.(RawSqlizer); ok {
-		return raw.ToSqlRaw()
-	} else {
+func nestedToSql(rc renderContext, s Sqlizer) (string, []any, error) {
+	switch s := s.(type) {
+	case contextSqlizer:
+		return s.toSqlContext(rc)
+	case RawSqlizer:
+		return s.ToSqlRaw()
+	default:
 		return s.ToSql()
 	}
 }
 
-func appendToSql(parts []Sqlizer, w io.Writer, sep string, args []any) ([]any, error) {
+// subqueryToSql is like nestedToSql, but parenthesizes subqueries, i.e.
+// SelectBuilder and CompoundBuilder.
+func subqueryToSql(rc renderContext, s Sqlizer) (string, []any, error) {
+	sql, args, err := nestedToSql(rc, s)
+	if err != nil {
+		return "", nil, err
+	}
+	switch s.(type) {
+	case SelectBuilder, CompoundBuilder:
+		sql = "(" + sql + ")"
+	}
+	return sql, args, nil
+}
+
+func appendToSql(rc renderContext, parts []Sqlizer, w io.Writer, sep string, args []any) ([]any, error) {
 	for i, p := range parts {
-		partSql, partArgs, err := nestedToSql(p)
+		partSql, partArgs, err := nestedToSql(rc, p)
 		if err != nil {
 			return nil, err
 		} else if len(partSql) == 0 {
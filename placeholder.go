@@ -2,6 +2,8 @@ package sq
 
 import (
 	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -9,12 +11,22 @@ import (
 //
 // ReplacePlaceholders takes a SQL statement and replaces each question mark
 // placeholder with a (possibly different) SQL placeholder.
+//
+// Question marks inside string literals, quoted identifiers and comments are
+// not placeholders, and ?? can be used to escape a literal question mark
+// anywhere (e.g. for the PostgreSQL JSONB ? operator).
 type PlaceholderFormat interface {
 	ReplacePlaceholders(sql string) (string, error)
 }
 
-type placeholderDebugger interface {
-	debugPlaceholder() string
+// ArgAwarePlaceholderFormat is a PlaceholderFormat which may also rewrite the
+// args bound to the placeholders it replaces.
+//
+// Builders call ReplacePlaceholdersArgs instead of ReplacePlaceholders when
+// their PlaceholderFormat implements it.
+type ArgAwarePlaceholderFormat interface {
+	PlaceholderFormat
+	ReplacePlaceholdersArgs(sql string, args []any) (string, []any, error)
 }
 
 var (
@@ -33,6 +45,14 @@ var (
 	// AtP is a PlaceholderFormat instance that replaces placeholders with
 	// "@p"-prefixed positional placeholders (e.g. @p1, @p2, @p3).
 	AtP = atpFormat{}
+
+	// DollarDedup is an ArgAwarePlaceholderFormat instance that replaces
+	// placeholders like Dollar, but binds equal comparable args only once and
+	// reuses their placeholder (e.g. "a = $1 OR b = $1").
+	//
+	// PostgreSQL deduces a single type for each parameter, so a reused
+	// placeholder must be used in places that agree on the type of the arg.
+	DollarDedup = dollarDedupFormat{}
 )
 
 type questionFormat struct{}
@@ -41,38 +61,77 @@ func (questionFormat) ReplacePlaceholders(sql string) (string, error) {
 	return sql, nil
 }
 
-func (questionFormat) debugPlaceholder() string {
-	return "?"
-}
-
 type dollarFormat struct{}
 
 func (dollarFormat) ReplacePlaceholders(sql string) (string, error) {
 	return replacePositionalPlaceholders(sql, "$")
 }
 
-func (dollarFormat) debugPlaceholder() string {
-	return "$"
-}
-
 type colonFormat struct{}
 
 func (colonFormat) ReplacePlaceholders(sql string) (string, error) {
 	return replacePositionalPlaceholders(sql, ":")
 }
 
-func (colonFormat) debugPlaceholder() string {
-	return ":"
-}
-
 type atpFormat struct{}
 
 func (atpFormat) ReplacePlaceholders(sql string) (string, error) {
 	return replacePositionalPlaceholders(sql, "@p")
 }
 
-func (atpFormat) debugPlaceholder() string {
-	return "@p"
+type dollarDedupFormat struct{}
+
+func (dollarDedupFormat) ReplacePlaceholders(sql string) (string, error) {
+	return replacePositionalPlaceholders(sql, "$")
+}
+
+func (dollarDedupFormat) ReplacePlaceholdersArgs(sql string, args []any) (string, []any, error) {
+	b := &strings.Builder{}
+	b.Grow(len(sql))
+	seen := make(map[any]int, len(args))
+	dedup := make([]any, 0, len(args))
+	i := 0
+	lexSql(sql, false, func(tok sqlToken, text string) {
+		switch tok {
+		case sqlText:
+			b.WriteString(text)
+		case sqlPlaceholder:
+			n := 0
+			if i < len(args) {
+				arg := args[i]
+				comparable := arg == nil || reflect.ValueOf(arg).Comparable()
+				if comparable {
+					n = seen[arg]
+				}
+				if n == 0 {
+					dedup = append(dedup, arg)
+					n = len(dedup)
+					if comparable {
+						seen[arg] = n
+					}
+				}
+			}
+			i++
+			b.WriteString("$")
+			b.WriteString(strconv.Itoa(n))
+		case sqlEscape:
+			b.WriteString("?")
+		}
+	})
+	if i != len(args) {
+		return "", nil, fmt.Errorf("statement has %d placeholders but %d args", i, len(args))
+	}
+	return b.String(), dedup, nil
+}
+
+// replacePlaceholders replaces the placeholders of a statement using f,
+// rewriting args if f is an ArgAwarePlaceholderFormat.
+func replacePlaceholders(f PlaceholderFormat, sql string, args []any) (string, []any, error) {
+	if af, ok := f.(ArgAwarePlaceholderFormat); ok {
+		return af.ReplacePlaceholdersArgs(sql, args)
+	}
+	sql, err := f.ReplacePlaceholders(sql)
+	return sql, args, err
 }
 
 // Placeholders returns a string with count ? placeholders joined with commas.
@@ -86,28 +145,19 @@ func Placeholders(count int) string {
 
 func replacePositionalPlaceholders(sql, prefix string) (string, error) {
 	b := &strings.Builder{}
+	b.Grow(len(sql))
 	i := 0
-	for {
-		p := strings.Index(sql, "?")
-		if p == -1 {
-			break
-		}
-
-		if len(sql[p:]) > 1 && sql[p:p+2] == "??" { // escape ?? => ?
-			b.WriteString(sql[:p])
-			b.WriteString("?")
-			if len(sql[p:]) == 1 {
-				break
-			}
-			sql = sql[p+2:]
-		} else {
+	lexSql(sql, false, func(tok sqlToken, text string) {
+		switch tok {
+		case sqlText:
+			b.WriteString(text)
+		case sqlPlaceholder:
 			i++
-			b.WriteString(sql[:p])
-			fmt.Fprintf(b, "%s%d", prefix, i)
-			sql = sql[p+1:]
+			b.WriteString(prefix)
+			b.WriteString(strconv.Itoa(i))
+		case sqlEscape:
+			b.WriteString("?")
 		}
-	}
-
-	b.WriteString(sql)
+	})
 	return b.String(), nil
 }
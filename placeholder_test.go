@@ -31,6 +31,42 @@ func TestAtp(t *testing.T) {
 	require.Equal(t, "x = @p1 AND y = @p2", s)
 }
 
+func TestDollarDedup(t *testing.T) {
+	sql, args, err := DollarDedup.ReplacePlaceholdersArgs(
+		"a = ? AND b = ? AND c = ? AND d = ? AND e = ? AND f ?? 'k'",
+		[]any{1, "x", 1, []byte("y"), []byte("y")})
+	require.NoError(t, err)
+	require.Equal(t, "a = $1 AND b = $2 AND c = $1 AND d = $3 AND e = $4 AND f ? 'k'", sql)
+	require.Equal(t, []any{1, "x", []byte("y"), []byte("y")}, args)
+
+	_, _, err = DollarDedup.ReplacePlaceholdersArgs("a = ?", nil)
+	require.EqualError(t, err, "statement has 1 placeholders but 0 args")
+
+	s, _ := DollarDedup.ReplacePlaceholders("x = ? AND y = ?")
+	require.Equal(t, "x = $1 AND y = $2", s)
+}
+
+func TestDollarDedupBuilder(t *testing.T) {
+	sub := Select("id").From("projects").Where(Eq{"tenant_id": 7})
+	sql, args, err := Select("*").From("tasks").
+		Where(Eq{"tenant_id": 7}).
+		Where(Expr("project_id IN (?)", sub)).
+		Where("done = ?", false).
+		PlaceholderFormat(DollarDedup).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t,
+		"SELECT * FROM tasks WHERE tenant_id = $1 AND project_id IN (SELECT id FROM projects WHERE tenant_id = $1) AND done = $2",
+		sql)
+	require.Equal(t, []any{7, false}, args)
+
+	sql, args, err = Update("tasks").Set("owner_id", 7).Where(Eq{"tenant_id": 7}).
+		PlaceholderFormat(DollarDedup).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "UPDATE tasks SET owner_id = $1 WHERE tenant_id = $1", sql)
+	require.Equal(t, []any{7}, args)
+}
+
 func TestPlaceholders(t *testing.T) {
 	require.Equal(t, Placeholders(2), "?,?")
 }
@@ -38,19 +74,181 @@ func TestPlaceholders(t *testing.T) {
 func TestEscapeDollar(t *testing.T) {
 	sql := "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ??| array['?'] AND enabled = ?"
 	s, _ := Dollar.ReplacePlaceholders(sql)
-	require.Equal(t, "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ?| array['$1'] AND enabled = $2", s)
+	require.Equal(t, "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ?| array['?'] AND enabled = $1", s)
 }
 
 func TestEscapeColon(t *testing.T) {
 	sql := "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ??| array['?'] AND enabled = ?"
 	s, _ := Colon.ReplacePlaceholders(sql)
-	require.Equal(t, "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ?| array[':1'] AND enabled = :2", s)
+	require.Equal(t, "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ?| array['?'] AND enabled = :1", s)
 }
 
 func TestEscapeAtp(t *testing.T) {
 	sql := "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ??| array['?'] AND enabled = ?"
 	s, _ := AtP.ReplacePlaceholders(sql)
-	require.Equal(t, "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ?| array['@p1'] AND enabled = @p2", s)
+	require.Equal(t, "SELECT uuid, \"data\" #> '{tags}' AS tags FROM nodes WHERE  \"data\" -> 'tags' ?| array['?'] AND enabled = @p1", s)
+}
+
+func TestPlaceholdersSkipLiteralsAndComments(t *testing.T) {
+	testCases := []struct {
+		SQL      string
+		Expected string
+	}{
+		{"note = 'why?' AND id = ?", "note = 'why?' AND id = $1"},
+		{"note = 'it''s ?' AND id = ?", "note = 'it''s ?' AND id = $1"},
+		{`"a?" = ? AND "b""?" = ?`, `"a?" = $1 AND "b""?" = $2`},
+		{"`a?` = ?", "`a?` = $1"},
+		{"a = ? -- b = ?\nAND c = ?", "a = $1 -- b = ?\nAND c = $2"},
+		{"a = ? -- b = ?", "a = $1 -- b = ?"},
+		{"a = ? /* b = ? /* nested ? */ c = ? */ AND d = ?", "a = $1 /* b = ? /* nested ? */ c = ? */ AND d = $2"},
+		{"a = $$ ? $$ AND b = ?", "a = $$ ? $$ AND b = $1"},
+		{"a = $fn$ ' ? $$ $fn$ AND b = ?", "a = $fn$ ' ? $$ $fn$ AND b = $1"},
+		{"a = E'\\' ?' AND b = ?", "a = E'\\' ?' AND b = $1"},
+		{"a = '?\\' AND b = ?", "a = '?\\' AND b = $1"},
+		{"a = 'unterminated ?", "a = 'unterminated ?"},
+		{"data ?? 'key' AND 'x??' = ?", "data ? 'key' AND 'x?' = $1"},
+	}
+
+	for _, tc := range testCases {
+		s, err := Dollar.ReplacePlaceholders(tc.SQL)
+		require.NoError(t, err)
+		require.Equal(t, tc.Expected, s, tc.SQL)
+	}
+}
+
+func TestLexSqlRoundTrip(t *testing.T) {
+	sql := "SELECT '?', \"?\", ?, ?? -- ?\n/* ? */ $x$?$x$ FROM t WHERE a = ?"
+
+	var b strings.Builder
+	var tokens []sqlToken
+	lexSql(sql, false, func(tok sqlToken, text string) {
+		b.WriteString(text)
+		if tok != sqlText {
+			tokens = append(tokens, tok)
+		}
+	})
+	require.Equal(t, sql, b.String())
+	require.Equal(t, []sqlToken{sqlPlaceholder, sqlEscape, sqlPlaceholder}, tokens)
+}
+
+// placeholderFragment is a SQL fragment with a known number of placeholders.
+type placeholderFragment struct {
+	sql string
+	n   int
+}
+
+var (
+	// commonFragments mean the same with and without backslash escapes.
+	commonFragments = []placeholderFragment{
+		{"?", 1},
+		{"??", 0},
+		{"x = ?", 1},
+		{"(?,?)", 2},
+		{"AND", 0},
+		{"'why?'", 0},
+		{"'it''s ?'", 0},
+		{"`b?`", 0},
+		{"-- c ?\n", 0},
+		{"/* ? /* ? */ ? */", 0},
+		{"$$ ? $$", 0},
+		{"$t$ ' ? $t$", 0},
+		{"data ?? 'k?'", 0},
+	}
+
+	// standardFragments are lexed without backslash escapes.
+	standardFragments = []placeholderFragment{
+		{"E'\\' ?'", 0},
+		{"'?\\' = ?", 1},
+		{`"a?"`, 0},
+		{`"b""?"`, 0},
+	}
+
+	// backslashFragments are lexed with backslash escapes, as for MySQL.
+	backslashFragments = []placeholderFragment{
+		{"'it\\'s ?'", 0},
+		{"'\\\\' = ?", 1},
+		{`"say \"?\""`, 0},
+	}
+)
+
+func FuzzReplacePlaceholders(f *testing.F) {
+	f.Add([]byte{0, 1, 2, 3}, false)
+	f.Add([]byte{5, 6, 8, 9, 0, 13, 14, 15}, false)
+	f.Add([]byte{10, 11, 12, 16, 0}, false)
+	f.Add([]byte{13, 0, 14, 15, 2}, true)
+
+	f.Fuzz(func(t *testing.T, data []byte, backslash bool) {
+		fragments := append([]placeholderFragment{}, commonFragments...)
+		if backslash {
+			fragments = append(fragments, backslashFragments...)
+		} else {
+			fragments = append(fragments, standardFragments...)
+		}
+
+		// Build a statement whose placeholder count is known independently of
+		// the lexer.
+		parts := make([]string, len(data))
+		n := 0
+		for i, b := range data {
+			frag := fragments[int(b)%len(fragments)]
+			parts[i] = frag.sql
+			n += frag.n
+		}
+		sql := strings.Join(parts, " ")
+
+		lexed := 0
+		lexSql(sql, backslash, func(tok sqlToken, _ string) {
+			if tok == sqlPlaceholder {
+				lexed++
+			}
+		})
+		require.Equal(t, n, lexed, sql)
+
+		// A Sqlizer arg makes Expr expand the placeholders.
+		args := make([]any, n)
+		for i := range args {
+			args[i] = i
+		}
+		if n > 0 {
+			args[0] = Expr("(?)", 0)
+		}
+		rc := renderContext{dialect: Postgres}
+		if backslash {
+			rc.dialect = MySQL
+		}
+		_, outArgs, err := Expr(sql, args...).(expr).toSqlContext(rc)
+		require.NoError(t, err)
+		require.Len(t, outArgs, n)
+
+		if !backslash {
+			// Every placeholder is numbered exactly once.
+			s, err := AtP.ReplacePlaceholders(sql)
+			require.NoError(t, err)
+			require.Equal(t, n, strings.Count(s, "@p")-strings.Count(sql, "@p"))
+
+			require.False(t, strings.HasPrefix(Debug(Expr(sql, args...)), "[Debug error"))
+			require.True(t, strings.HasPrefix(Debug(Expr(sql, append(args, n)...)), "[Debug error"))
+		}
+	})
+}
+
+func TestExprBackslashEscapes(t *testing.T) {
+	b := Select("id").From("posts").Where(Expr(`title = 'it\'s ?' AND id IN (?)`, Select("post_id").From("tags").Where("tag = ?", "x")))
+
+	sql, args, err := b.Dialect(MySQL).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT id FROM posts WHERE title = 'it\'s ?' AND id IN (SELECT post_id FROM tags WHERE tag = ?)`, sql)
+	require.Equal(t, []any{"x"}, args)
+
+	// Without a dialect, the Question format implies backslash escapes.
+	sql, _, err = b.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT id FROM posts WHERE title = 'it\'s ?' AND id IN (SELECT post_id FROM tags WHERE tag = ?)`, sql)
+
+	// PostgreSQL doesn't have them outside of E'...' literals.
+	sql, _, err = Select("id").From("posts").Where(Expr(`path = 'C:\' AND id IN (?)`, Select("1"))).Dialect(Postgres).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT id FROM posts WHERE path = 'C:\' AND id IN (SELECT 1)`, sql)
 }
 
 func BenchmarkPlaceholdersArray(b *testing.B) {
@@ -0,0 +1,368 @@
+package sq
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
+
+// The predicates in this file compare a single column, so unlike the map
+// based predicates such as Eq, their SQL follows the order in which they are
+// added to a query, and their values are checked by the compiler.
+
+// EqCol returns a "col = value" predicate, or "col IS NULL" if v is nil.
+//
+// Ex:
+//
+//	Select("*").From("users").Where(EqCol("org_id", orgID))
+func EqCol[T any](col string, v T) Sqlizer {
+	return comparison{col: col, op: "=", nullOp: "IS", val: v}
+}
+
+// NotEqCol returns a "col <> value" predicate, or "col IS NOT NULL" if v is
+// nil.
+func NotEqCol[T any](col string, v T) Sqlizer {
+	return comparison{col: col, op: "<>", nullOp: "IS NOT", val: v}
+}
+
+// LtCol returns a "col < value" predicate.
+func LtCol[T any](col string, v T) Sqlizer {
+	return comparison{col: col, op: "<", val: v}
+}
+
+// LtOrEqCol returns a "col <= value" predicate.
+func LtOrEqCol[T any](col string, v T) Sqlizer {
+	return comparison{col: col, op: "<=", val: v}
+}
+
+// GtCol returns a "col > value" predicate.
+func GtCol[T any](col string, v T) Sqlizer {
+	return comparison{col: col, op: ">", val: v}
+}
+
+// GtOrEqCol returns a "col >= value" predicate.
+func GtOrEqCol[T any](col string, v T) Sqlizer {
+	return comparison{col: col, op: ">=", val: v}
+}
+
+// In returns a "col IN (values...)" predicate, which is always false if vs is
+// empty. See WithArrayParams to pass vs as a single array parameter.
+func In[T any](col string, vs []T) Sqlizer {
+	return inList{col: col, slice: vs, vals: anySlice(vs)}
+}
+
+// NotIn returns a "col NOT IN (values...)" predicate, which is always true if
+// vs is empty.
+func NotIn[T any](col string, vs []T) Sqlizer {
+	return inList{col: col, slice: vs, vals: anySlice(vs), not: true}
+}
+
+// Between returns a "col BETWEEN low AND high" predicate.
+func Between[T any](col string, low, high T) Sqlizer {
+	return between{col: col, low: low, high: high}
+}
+
+// NotBetween returns a "col NOT BETWEEN low AND high" predicate.
+func NotBetween[T any](col string, low, high T) Sqlizer {
+	return between{col: col, low: low, high: high, not: true}
+}
+
+// IsDistinctFrom returns a "col IS DISTINCT FROM value" predicate, which is
+// like "col <> value", but treats NULL as a value. It is rendered with the <=>
+// operator for MySQL.
+func IsDistinctFrom[T any](col string, v T) Sqlizer {
+	return distinctFrom{col: col, val: v}
+}
+
+// IsNotDistinctFrom returns a "col IS NOT DISTINCT FROM value" predicate. See
+// IsDistinctFrom.
+func IsNotDistinctFrom[T any](col string, v T) Sqlizer {
+	return distinctFrom{col: col, val: v, not: true}
+}
+
+// Not negates a predicate, as in "NOT (pred)".
+func Not(pred Sqlizer) Sqlizer {
+	return not{pred}
+}
+
+// Exists returns an "EXISTS (subquery)" predicate.
+//
+// Ex:
+//
+//	Select("id").From("users u").Where(Exists(Select("1").From("orders o").Where("o.user_id = u.id")))
+//	// SELECT id FROM users u WHERE EXISTS (SELECT 1 FROM orders o WHERE o.user_id = u.id)
+func Exists(sub Sqlizer) Sqlizer {
+	return subqueryPredicate{prefix: "EXISTS ", sub: sub}
+}
+
+// NotExists returns a "NOT EXISTS (subquery)" predicate.
+func NotExists(sub Sqlizer) Sqlizer {
+	return subqueryPredicate{prefix: "NOT EXISTS ", sub: sub}
+}
+
+// InSelect returns a "col IN (subquery)" predicate.
+func InSelect(col string, sub Sqlizer) Sqlizer {
+	return subqueryPredicate{prefix: col + " IN ", sub: sub}
+}
+
+// NotInSelect returns a "col NOT IN (subquery)" predicate. Note that it is
+// never true if the subquery returns a NULL.
+func NotInSelect(col string, sub Sqlizer) Sqlizer {
+	return subqueryPredicate{prefix: col + " NOT IN ", sub: sub}
+}
+
+// AnyOf returns a "col op ANY (subquery)" predicate, which is true if the
+// comparison is true for any row of the subquery, e.g.
+// AnyOf("price", ">", sub).
+func AnyOf(col, op string, sub Sqlizer) Sqlizer {
+	return subqueryPredicate{prefix: col + " " + op + " ANY ", op: op, sub: sub}
+}
+
+// AllOf returns a "col op ALL (subquery)" predicate, which is true if the
+// comparison is true for every row of the subquery. See AnyOf.
+func AllOf(col, op string, sub Sqlizer) Sqlizer {
+	return subqueryPredicate{prefix: col + " " + op + " ALL ", op: op, sub: sub}
+}
+
+func anySlice[T any](vs []T) []any {
+	vals := make([]any, len(vs))
+	for i, v := range vs {
+		vals[i] = v
+	}
+	return vals
+}
+
+// predicateValue resolves driver.Valuer and pointer values, as Eq does.
+func predicateValue(v any) (any, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		if rv := reflect.ValueOf(valuer); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return nil, nil
+		}
+		return valuer.Value()
+	}
+
+	r := reflect.ValueOf(v)
+	if r.Kind() == reflect.Ptr {
+		if r.IsNil() {
+			return nil, nil
+		}
+		return r.Elem().Interface(), nil
+	}
+	return v, nil
+}
+
+// operandToSql renders a predicate value resolved by predicateValue: a nested
+// Sqlizer, NULL for nil or else a placeholder.
+func operandToSql(rc renderContext, v any) (string, []any, error) {
+	switch v := v.(type) {
+	case nil:
+		return "NULL", nil, nil
+	case Sqlizer:
+		return subqueryToSql(rc, v)
+	default:
+		return "?", []any{v}, nil
+	}
+}
+
+type comparison struct {
+	col string
+	op  string
+	// nullOp is the operator used in place of op for a nil value. A nil value
+	// is an error if it is empty.
+	nullOp string
+	val    any
+}
+
+func (c comparison) ToSql() (string, []any, error) {
+	return c.toSqlContext(renderContext{})
+}
+
+func (c comparison) toSqlContext(rc renderContext) (string, []any, error) {
+	val, err := predicateValue(c.val)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if val == nil {
+		if len(c.nullOp) == 0 {
+			return "", nil, fmt.Errorf("cannot use null with %s operator", c.op)
+		}
+		return fmt.Sprintf("%s %s NULL", c.col, c.nullOp), nil, nil
+	}
+
+	sql, args, err := operandToSql(rc, val)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return fmt.Sprintf("%s %s %s", c.col, c.op, sql), args, nil
+}
+
+type inList struct {
+	col string
+	// slice is the original slice, which is passed to the ArrayFunc of
+	// WithArrayParams.
+	slice any
+	vals  []any
+	not   bool
+}
+
+func (in inList) ToSql() (string, []any, error) {
+	return in.toSqlContext(renderContext{})
+}
+
+func (in inList) toSqlContext(rc renderContext) (string, []any, error) {
+	if len(in.vals) == 0 {
+		return rc.boolLiteral(in.not), []any{}, nil
+	}
+
+	if wrap := rc.arrayFunc(); wrap != nil {
+		if err := rc.require(FeatureArrayParams); err != nil {
+			return "", nil, err
+		}
+		opr := "= ANY"
+		if in.not {
+			opr = "<> ALL"
+		}
+		return fmt.Sprintf("%s %s(?)", in.col, opr), []any{wrap(in.slice)}, nil
+	}
+
+	opr := "IN"
+	if in.not {
+		opr = "NOT IN"
+	}
+
+	return fmt.Sprintf("%s %s (%s)", in.col, opr, Placeholders(len(in.vals))), in.vals, nil
+}
+
+type between struct {
+	col       string
+	low, high any
+	not       bool
+}
+
+func (b between) ToSql() (string, []any, error) {
+	return b.toSqlContext(renderContext{})
+}
+
+func (b between) toSqlContext(rc renderContext) (string, []any, error) {
+	low, err := predicateValue(b.low)
+	if err != nil {
+		return "", nil, err
+	}
+	high, err := predicateValue(b.high)
+	if err != nil {
+		return "", nil, err
+	}
+	if low == nil || high == nil {
+		return "", nil, fmt.Errorf("cannot use null with BETWEEN operator")
+	}
+
+	lowSql, args, err := operandToSql(rc, low)
+	if err != nil {
+		return "", nil, err
+	}
+	highSql, highArgs, err := operandToSql(rc, high)
+	if err != nil {
+		return "", nil, err
+	}
+
+	opr := "BETWEEN"
+	if b.not {
+		opr = "NOT BETWEEN"
+	}
+
+	return fmt.Sprintf("%s %s %s AND %s", b.col, opr, lowSql, highSql), append(args, highArgs...), nil
+}
+
+type distinctFrom struct {
+	col string
+	val any
+	not bool
+}
+
+func (d distinctFrom) ToSql() (string, []any, error) {
+	return d.toSqlContext(renderContext{})
+}
+
+func (d distinctFrom) toSqlContext(rc renderContext) (string, []any, error) {
+	val, err := predicateValue(d.val)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sql, args, err := operandToSql(rc, val)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if rc.dialect != nil && !rc.dialect.Supports(FeatureDistinctFrom) && rc.dialect.Supports(FeatureNullSafeEqual) {
+		if d.not {
+			return fmt.Sprintf("%s <=> %s", d.col, sql), args, nil
+		}
+		return fmt.Sprintf("NOT (%s <=> %s)", d.col, sql), args, nil
+	}
+
+	if err := rc.require(FeatureDistinctFrom); err != nil {
+		return "", nil, err
+	}
+
+	opr := "IS DISTINCT FROM"
+	if d.not {
+		opr = "IS NOT DISTINCT FROM"
+	}
+
+	return fmt.Sprintf("%s %s %s", d.col, opr, sql), args, nil
+}
+
+type not struct {
+	pred Sqlizer
+}
+
+func (n not) ToSql() (string, []any, error) {
+	return n.toSqlContext(renderContext{})
+}
+
+func (n not) toSqlContext(rc renderContext) (string, []any, error) {
+	sql, args, err := nestedToSql(rc, n.pred)
+	if err != nil || len(sql) == 0 {
+		return sql, args, err
+	}
+	return fmt.Sprintf("NOT (%s)", sql), args, nil
+}
+
+// comparisonOps are the operators accepted by AnyOf and AllOf.
+var comparisonOps = map[string]bool{
+	"=": true, "<>": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true,
+}
+
+// subqueryPredicate is a predicate on a parenthesized subquery, which follows
+// prefix.
+type subqueryPredicate struct {
+	prefix string
+	// op is the comparison operator of ANY and ALL predicates.
+	op  string
+	sub Sqlizer
+}
+
+func (p subqueryPredicate) ToSql() (string, []any, error) {
+	return p.toSqlContext(renderContext{})
+}
+
+func (p subqueryPredicate) toSqlContext(rc renderContext) (string, []any, error) {
+	if len(p.op) > 0 {
+		if !comparisonOps[p.op] {
+			return "", nil, fmt.Errorf("invalid comparison operator %q", p.op)
+		}
+		if err := rc.require(FeatureAnyAll); err != nil {
+			return "", nil, err
+		}
+	}
+
+	sql, args, err := nestedToSql(rc, p.sub)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return p.prefix + "(" + sql + ")", args, nil
+}
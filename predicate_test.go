@@ -0,0 +1,142 @@
+package sq
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPredicates(t *testing.T) {
+	var nilName *string
+	name := "sq"
+
+	tests := []struct {
+		name string
+		pred Sqlizer
+		sql  string
+		args []any
+	}{
+		{"EqCol", EqCol("id", 1), "id = ?", []any{1}},
+		{"EqColPtr", EqCol("name", &name), "name = ?", []any{"sq"}},
+		{"EqColNil", EqCol("name", nilName), "name IS NULL", nil},
+		{"EqColNullValuer", EqCol("name", sql.NullString{}), "name IS NULL", nil},
+		{"EqColExpr", EqCol[Sqlizer]("created_at", Expr("now()")), "created_at = now()", nil},
+		{"EqColExprNull", EqCol[Sqlizer]("name", Expr("NULL")), "name = NULL", nil},
+		{"BetweenExprNull", Between[Sqlizer]("age", Expr("NULL"), Expr("?", 65)), "age BETWEEN NULL AND ?", []any{65}},
+		{"NotEqCol", NotEqCol("id", 1), "id <> ?", []any{1}},
+		{"NotEqColNil", NotEqCol("name", nilName), "name IS NOT NULL", nil},
+		{"LtCol", LtCol("id", 1), "id < ?", []any{1}},
+		{"LtOrEqCol", LtOrEqCol("id", 1), "id <= ?", []any{1}},
+		{"GtCol", GtCol("id", 1), "id > ?", []any{1}},
+		{"GtOrEqCol", GtOrEqCol("id", 1), "id >= ?", []any{1}},
+		{"In", In("id", []int{1, 2, 3}), "id IN (?,?,?)", []any{1, 2, 3}},
+		{"InEmpty", In("id", []int{}), "(1=0)", []any{}},
+		{"NotIn", NotIn("id", []string{"a"}), "id NOT IN (?)", []any{"a"}},
+		{"NotInEmpty", NotIn[int]("id", nil), "(1=1)", []any{}},
+		{"Between", Between("age", 18, 65), "age BETWEEN ? AND ?", []any{18, 65}},
+		{"NotBetween", NotBetween("age", 18, 65), "age NOT BETWEEN ? AND ?", []any{18, 65}},
+		{"IsDistinctFrom", IsDistinctFrom("name", &name), "name IS DISTINCT FROM ?", []any{"sq"}},
+		{"IsNotDistinctFrom", IsNotDistinctFrom("name", nilName), "name IS NOT DISTINCT FROM NULL", nil},
+		{"Not", Not(Or{EqCol("a", 1), EqCol("b", 2)}), "NOT ((a = ? OR b = ?))", []any{1, 2}},
+		{"NotEmpty", Not(And{}), "NOT ((1=1))", []any{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sql, args, err := test.pred.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.sql, sql)
+			require.Equal(t, test.args, args)
+		})
+	}
+}
+
+func TestPredicates_Errors(t *testing.T) {
+	var nilID *int
+
+	_, _, err := LtCol("id", nilID).ToSql()
+	require.EqualError(t, err, "cannot use null with < operator")
+
+	_, _, err = Between("id", nilID, nilID).ToSql()
+	require.EqualError(t, err, "cannot use null with BETWEEN operator")
+}
+
+func TestPredicates_Order(t *testing.T) {
+	// Predicates keep the order they are added in.
+	sql, args, err := Select("*").From("users").
+		Where(EqCol("status", "active")).
+		Where(And{In("org_id", []int64{3, 1}), GtCol("age", 18)}).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM users WHERE status = ? AND (org_id IN (?,?) AND age > ?)", sql)
+	require.Equal(t, []any{"active", int64(3), int64(1), 18}, args)
+}
+
+func TestIsDistinctFrom_Dialects(t *testing.T) {
+	b := Select("id").From("users").Where(IsDistinctFrom("name", "sq")).Where(IsNotDistinctFrom("email", "a@example.com"))
+
+	sql, _, err := b.Dialect(Postgres).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT id FROM users WHERE name IS DISTINCT FROM $1 AND email IS NOT DISTINCT FROM $2", sql)
+
+	sql, _, err = b.Dialect(MySQL).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT id FROM users WHERE NOT (name <=> ?) AND email <=> ?", sql)
+
+	_, _, err = b.Dialect(Oracle).ToSql()
+	require.EqualError(t, err, "IS DISTINCT FROM is not supported by Oracle")
+}
+
+func TestSubqueryPredicates(t *testing.T) {
+	sub := Select("user_id").From("orders").Where("total > ?", 100)
+
+	tests := []struct {
+		name string
+		pred Sqlizer
+		sql  string
+	}{
+		{"Exists", Exists(sub), "EXISTS (SELECT user_id FROM orders WHERE total > ?)"},
+		{"NotExists", NotExists(sub), "NOT EXISTS (SELECT user_id FROM orders WHERE total > ?)"},
+		{"InSelect", InSelect("id", sub), "id IN (SELECT user_id FROM orders WHERE total > ?)"},
+		{"NotInSelect", NotInSelect("id", sub), "id NOT IN (SELECT user_id FROM orders WHERE total > ?)"},
+		{"AnyOf", AnyOf("id", "=", sub), "id = ANY (SELECT user_id FROM orders WHERE total > ?)"},
+		{"AllOf", AllOf("id", "<>", sub), "id <> ALL (SELECT user_id FROM orders WHERE total > ?)"},
+		{"Eq", Eq{"id": sub}, "id = (SELECT user_id FROM orders WHERE total > ?)"},
+		{"EqCol", EqCol("id", sub), "id = (SELECT user_id FROM orders WHERE total > ?)"},
+		{"Gt", Gt{"id": sub}, "id > (SELECT user_id FROM orders WHERE total > ?)"},
+		{"InSelectCompound", InSelect("id", UnionAll(sub, Select("id").From("admins"))),
+			"id IN (SELECT user_id FROM orders WHERE total > ? UNION ALL SELECT id FROM admins)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sql, args, err := test.pred.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.sql, sql)
+			require.Equal(t, []any{100}, args)
+		})
+	}
+}
+
+func TestSubqueryPredicates_Placeholders(t *testing.T) {
+	sub := Select("1").From("orders o").Where("o.user_id = u.id").Where("o.total > ?", 100).PlaceholderFormat(Dollar)
+
+	sql, args, err := Select("id").From("users u").
+		Where("u.org_id = ?", 1).
+		Where(Exists(sub)).
+		Where(AllOf("u.credit", ">=", Select("total").From("orders").Where("status = ?", "open"))).
+		PlaceholderFormat(Dollar).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT id FROM users u WHERE u.org_id = $1 "+
+		"AND EXISTS (SELECT 1 FROM orders o WHERE o.user_id = u.id AND o.total > $2) "+
+		"AND u.credit >= ALL (SELECT total FROM orders WHERE status = $3)", sql)
+	require.Equal(t, []any{1, 100, "open"}, args)
+
+	_, _, err = Select("id").From("users").Where(AnyOf("id", "LIKE", sub)).ToSql()
+	require.EqualError(t, err, `invalid comparison operator "LIKE"`)
+
+	_, _, err = Select("id").From("users").Where(AnyOf("id", "=", sub)).Dialect(SQLite).ToSql()
+	require.EqualError(t, err, "ANY/ALL is not supported by SQLite")
+}
@@ -0,0 +1,63 @@
+package sq
+
+import (
+	"strings"
+)
+
+// returningPart is a column or expression of a RETURNING (or OUTPUT) clause.
+type returningPart struct {
+	column string
+	expr   Sqlizer
+}
+
+// useOutput reports whether RETURNING clauses are rendered as OUTPUT clauses.
+func (rc renderContext) useOutput() bool {
+	return rc.dialect != nil && rc.dialect.Supports(FeatureOutput)
+}
+
+// returningToSql renders a RETURNING clause, or an OUTPUT clause for dialects
+// which support it, in which case columns are qualified with the given pseudo
+// table (INSERTED or DELETED) unless they are already qualified.
+func returningToSql(rc renderContext, parts []returningPart, pseudo string) (sql string, args []any, err error) {
+	if len(parts) == 0 {
+		return
+	}
+
+	keyword := "RETURNING "
+	output := rc.useOutput()
+	if output {
+		keyword = "OUTPUT "
+	} else if err = rc.require(FeatureReturning); err != nil {
+		return
+	}
+
+	sqls := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p.expr == nil {
+			col := p.column
+			if output && !strings.Contains(col, ".") {
+				col = pseudo + "." + col
+			}
+			sqls = append(sqls, col)
+			continue
+		}
+
+		pSql, pArgs, err := nestedToSql(rc, p.expr)
+		if err != nil {
+			return "", nil, err
+		}
+		sqls = append(sqls, pSql)
+		args = append(args, pArgs...)
+	}
+
+	sql = keyword + strings.Join(sqls, ", ")
+	return
+}
+
+func returningColumns(columns []string) []returningPart {
+	parts := make([]returningPart, len(columns))
+	for i, col := range columns {
+		parts[i] = returningPart{column: col}
+	}
+	return parts
+}
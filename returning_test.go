@@ -0,0 +1,82 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReturning(t *testing.T) {
+	tests := []struct {
+		name string
+		b    Sqlizer
+		sql  string
+		args []any
+	}{
+		{
+			name: "insert",
+			b: Insert("users").Columns("name").Values("a").
+				OnConflict("name").DoNothing().
+				Returning("id", "created_at").Suffix("-- x"),
+			sql:  "INSERT INTO users (name) VALUES (?) ON CONFLICT (name) DO NOTHING RETURNING id, created_at -- x",
+			args: []any{"a"},
+		},
+		{
+			name: "update",
+			b: Update("users").Set("name", "a").Where(Eq{"id": 1}).
+				ReturningExpr(Expr("name || ?", "!")).Limit(1),
+			sql:  "UPDATE users SET name = ? WHERE id = ? RETURNING name || ? LIMIT 1",
+			args: []any{"a", 1, "!"},
+		},
+		{
+			name: "delete",
+			b:    Delete("users").Where(Eq{"id": 1}).Returning("*").Suffix("-- x"),
+			sql:  "DELETE FROM users WHERE id = ? RETURNING * -- x",
+			args: []any{1},
+		},
+		{
+			name: "sql server insert",
+			b: Insert("users").Columns("name").Values("a").
+				Returning("id", "DELETED.x").Dialect(SQLServer),
+			sql:  "INSERT INTO users (name) OUTPUT INSERTED.id, DELETED.x VALUES (@p1)",
+			args: []any{"a"},
+		},
+		{
+			name: "sql server insert select",
+			b: Insert("users").Columns("name").Select(Select("name").From("old")).
+				ReturningExpr(Expr("INSERTED.id + ?", 1)).Dialect(SQLServer),
+			sql:  "INSERT INTO users (name) OUTPUT INSERTED.id + @p1 SELECT name FROM old",
+			args: []any{1},
+		},
+		{
+			name: "sql server update",
+			b: Update("users").Set("name", "a").From("other").Where(Eq{"id": 1}).
+				Returning("id", "DELETED.name").Dialect(SQLServer),
+			sql:  "UPDATE users SET name = @p1 OUTPUT INSERTED.id, DELETED.name FROM other WHERE id = @p2",
+			args: []any{"a", 1},
+		},
+		{
+			name: "sql server delete",
+			b:    Delete("users").Where(Eq{"id": 1}).Returning("*").Limit(5).Dialect(SQLServer),
+			sql:  "DELETE TOP (5) FROM users OUTPUT DELETED.* WHERE id = @p1",
+			args: []any{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.b.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, tt.sql, sql)
+			require.Equal(t, tt.args, args)
+		})
+	}
+}
+
+func TestReturningNotSupported(t *testing.T) {
+	_, _, err := Insert("users").Values(1).Returning("id").Dialect(MySQL).ToSql()
+	require.EqualError(t, err, "RETURNING is not supported by MySQL")
+
+	_, _, err = Delete("users").Returning("id").Dialect(Oracle).ToSql()
+	require.EqualError(t, err, "RETURNING is not supported by Oracle")
+}
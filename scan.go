@@ -0,0 +1,150 @@
+package sq
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// Get runs the query q with runner and scans the first result row into a T.
+//
+// If T is a struct (other than a sql.Scanner or driver.Valuer such as
+// sql.NullString or time.Time), each result column is scanned into the field
+// with the matching db tag, e.g.:
+//
+//	type User struct {
+//		ID    int64          `db:"id"`
+//		Email sql.NullString `db:"email"`
+//	}
+//
+//	user, err := sq.Get[User](ctx, db, sq.Select("id", "email").From("users"))
+//
+// The fields of embedded structs without a db tag are mapped as if they were
+// declared on the outer struct. It is an error for a result column to have no
+// matching field.
+//
+// Otherwise the query must return a single column which is scanned directly
+// into a T.
+//
+// Get returns sql.ErrNoRows if the query returns no rows.
+func Get[T any](ctx context.Context, runner Runner, q Sqlizer) (T, error) {
+	var zero T
+
+	rows, err := queryContext(ctx, runner, q)
+	if err != nil {
+		return zero, err
+	}
+	defer rows.Close()
+
+	s, err := newRowScanner[T](rows)
+	if err != nil {
+		return zero, err
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return zero, err
+		}
+		return zero, sql.ErrNoRows
+	}
+
+	v, err := s.scan(rows)
+	if err != nil {
+		return zero, err
+	}
+
+	return v, rows.Close()
+}
+
+// GetAll runs the query q with runner and scans every result row into a T.
+//
+// See Get for how rows are mapped to T.
+func GetAll[T any](ctx context.Context, runner Runner, q Sqlizer) ([]T, error) {
+	rows, err := queryContext(ctx, runner, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	s, err := newRowScanner[T](rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []T
+	for rows.Next() {
+		v, err := s.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, rows.Close()
+}
+
+func queryContext(ctx context.Context, runner Runner, q Sqlizer) (*sql.Rows, error) {
+	if runner == nil {
+		return nil, ErrRunnerNotSet
+	}
+	return QueryContextWith(ctx, runner, q)
+}
+
+// rowScanner scans result rows into values of type T.
+type rowScanner[T any] struct {
+	// fields holds the field index for each result column when T is a struct.
+	fields [][]int
+}
+
+func newRowScanner[T any](rows *sql.Rows) (*rowScanner[T], error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if isScanLeaf(t) {
+		if len(columns) != 1 {
+			return nil, fmt.Errorf("cannot scan %d columns into %s", len(columns), t)
+		}
+		return &rowScanner[T]{}, nil
+	}
+
+	info, err := getStructInfo(t)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([][]int, len(columns))
+	for i, column := range columns {
+		field, ok := info.byColumn[column]
+		if !ok {
+			return nil, fmt.Errorf("missing destination for column %q in %s", column, t)
+		}
+		fields[i] = field.index
+	}
+
+	return &rowScanner[T]{fields: fields}, nil
+}
+
+func (s *rowScanner[T]) scan(rows *sql.Rows) (T, error) {
+	var v T
+
+	if s.fields == nil {
+		err := rows.Scan(&v)
+		return v, err
+	}
+
+	rv := reflect.ValueOf(&v).Elem()
+	dest := make([]any, len(s.fields))
+	for i, index := range s.fields {
+		dest[i] = fieldByIndex(rv, index).Addr().Interface()
+	}
+
+	err := rows.Scan(dest...)
+	return v, err
+}
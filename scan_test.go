@@ -0,0 +1,126 @@
+package sq
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type scanBase struct {
+	ID int64 `db:"id"`
+}
+
+type ScanBase scanBase
+
+type scanUser struct {
+	scanBase
+	Email    sql.NullString `db:"email"`
+	Name     string         `db:"name"`
+	Ignored  string
+	Excluded string `db:"-"`
+}
+
+func TestGet(t *testing.T) {
+	db, fake := newFakeDB(
+		[]string{"id", "email", "name"},
+		[]driver.Value{int64(1), nil, "a"},
+		[]driver.Value{int64(2), "b@example.com", "b"},
+	)
+
+	q := Select("id", "email", "name").From("users").Where(Eq{"id": 1}).PlaceholderFormat(Dollar)
+	user, err := Get[scanUser](context.Background(), db, q)
+	require.NoError(t, err)
+	require.Equal(t, scanUser{scanBase: scanBase{ID: 1}, Name: "a"}, user)
+
+	require.Equal(t, []string{"SELECT id, email, name FROM users WHERE id = $1"}, fake.queries)
+}
+
+func TestGetAll(t *testing.T) {
+	db, _ := newFakeDB(
+		[]string{"id", "email", "name"},
+		[]driver.Value{int64(1), nil, "a"},
+		[]driver.Value{int64(2), "b@example.com", "b"},
+	)
+
+	users, err := GetAll[scanUser](context.Background(), db, Select("id", "email", "name").From("users"))
+	require.NoError(t, err)
+	require.Equal(t, []scanUser{
+		{scanBase: scanBase{ID: 1}, Name: "a"},
+		{scanBase: scanBase{ID: 2}, Email: sql.NullString{String: "b@example.com", Valid: true}, Name: "b"},
+	}, users)
+}
+
+func TestGetScalar(t *testing.T) {
+	db, _ := newFakeDB([]string{"count"}, []driver.Value{int64(3)})
+
+	n, err := Get[int](context.Background(), db, Select("COUNT(*)").From("users"))
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+
+	db, _ = newFakeDB([]string{"id", "name"}, []driver.Value{int64(3), "c"})
+	_, err = Get[int](context.Background(), db, Select("id", "name").From("users"))
+	require.EqualError(t, err, "cannot scan 2 columns into int")
+}
+
+func TestGetEmbeddedPointer(t *testing.T) {
+	type user struct {
+		*ScanBase
+		Name string `db:"name"`
+	}
+
+	db, _ := newFakeDB([]string{"id", "name"}, []driver.Value{int64(4), "d"})
+
+	u, err := Get[user](context.Background(), db, Select("id", "name").From("users"))
+	require.NoError(t, err)
+	require.Equal(t, user{ScanBase: &ScanBase{ID: 4}, Name: "d"}, u)
+}
+
+func TestGetNoRows(t *testing.T) {
+	db, _ := newFakeDB([]string{"id"})
+
+	_, err := Get[scanUser](context.Background(), db, Select("id").From("users"))
+	require.Equal(t, sql.ErrNoRows, err)
+
+	users, err := GetAll[scanUser](context.Background(), db, Select("id").From("users"))
+	require.NoError(t, err)
+	require.Empty(t, users)
+}
+
+func TestGetUnmappedColumn(t *testing.T) {
+	db, _ := newFakeDB([]string{"id", "other"}, []driver.Value{int64(1), "x"})
+
+	_, err := Get[scanUser](context.Background(), db, Select("id", "other").From("users"))
+	require.EqualError(t, err, `missing destination for column "other" in sq.scanUser`)
+}
+
+func TestGetErrors(t *testing.T) {
+	_, err := Get[scanUser](context.Background(), nil, Select("id"))
+	require.Equal(t, ErrRunnerNotSet, err)
+
+	db, _ := newFakeDB([]string{"id"})
+	_, err = GetAll[scanUser](context.Background(), db, Select())
+	require.Error(t, err)
+}
+
+func TestGetStructInfoShadowing(t *testing.T) {
+	type outer struct {
+		scanBase
+		ID string `db:"id"`
+	}
+
+	info, err := getStructInfo(reflect.TypeOf(outer{}))
+	require.NoError(t, err)
+	require.Len(t, info.fields, 1)
+	require.Equal(t, []int{1}, info.byColumn["id"].index)
+
+	type dup struct {
+		A int `db:"a"`
+		B int `db:"a"`
+	}
+	_, err = getStructInfo(reflect.TypeOf(dup{}))
+	require.Error(t, err)
+}
@@ -0,0 +1,323 @@
+package sq
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/userhubdev/sq/internal/builder"
+)
+
+// SortKey is a column of a keyset pagination order, see SeekAfter. The
+// columns of the order must be NOT NULL, and together unique, e.g. end with
+// the primary key.
+type SortKey struct {
+	Column string
+	Desc   bool
+}
+
+// SortAsc returns an ascending SortKey.
+func SortAsc(column string) SortKey {
+	return SortKey{Column: column}
+}
+
+// SortDesc returns a descending SortKey.
+func SortDesc(column string) SortKey {
+	return SortKey{Column: column, Desc: true}
+}
+
+func (k SortKey) String() string {
+	if k.Desc {
+		return k.Column + " DESC"
+	}
+	return k.Column + " ASC"
+}
+
+// Cursor is the sort key values of a row, which a page of keyset pagination
+// starts after or ends before. See CursorCodec to pass it to clients.
+type Cursor []any
+
+// SeekAfter orders the query by keys and, if cursor is not empty, restricts it
+// to the rows after cursor, which holds the values of keys of the last row of
+// the previous page. Use it with Limit to get the next page.
+//
+// The restriction is a row value comparison, e.g. "(created_at, id) > (?,?)",
+// when all keys have the same direction and the Dialect supports it, or else
+// the equivalent "created_at > ? OR (created_at = ? AND id > ?)".
+//
+// Ex:
+//
+//	Select("*").From("events").SeekAfter(cursor, SortDesc("created_at"), SortDesc("id")).Limit(50)
+//	// SELECT * FROM events WHERE (created_at, id) < (?,?) ORDER BY created_at DESC, id DESC LIMIT 50
+func (b SelectBuilder) SeekAfter(cursor Cursor, keys ...SortKey) SelectBuilder {
+	return b.seek(cursor, keys, false)
+}
+
+// SeekBefore is like SeekAfter, but restricts the query to the rows before
+// cursor, which holds the values of keys of the first row of the next page.
+// Use it with Limit to get the previous page.
+//
+// So that Limit keeps the rows closest to the cursor, the query is ordered by
+// the reverse of keys: reverse the rows to get them in the order of keys.
+func (b SelectBuilder) SeekBefore(cursor Cursor, keys ...SortKey) SelectBuilder {
+	return b.seek(cursor, keys, true)
+}
+
+func (b SelectBuilder) seek(cursor Cursor, keys []SortKey, before bool) SelectBuilder {
+	if len(cursor) > 0 {
+		b = builder.Append(b, "WhereParts", seekPredicate{keys: keys, values: cursor, before: before}).(SelectBuilder)
+	}
+
+	for _, k := range keys {
+		if before {
+			k.Desc = !k.Desc
+		}
+		b = b.OrderBy(k.String())
+	}
+
+	return b
+}
+
+// seekPredicate restricts a query to the rows after (or before) the given sort
+// key values.
+type seekPredicate struct {
+	keys   []SortKey
+	values []any
+	before bool
+}
+
+func (p seekPredicate) ToSql() (string, []any, error) {
+	return p.toSqlContext(renderContext{})
+}
+
+func (p seekPredicate) toSqlContext(rc renderContext) (string, []any, error) {
+	if len(p.keys) == 0 {
+		return "", nil, fmt.Errorf("seek requires at least one sort key")
+	}
+	if len(p.values) != len(p.keys) {
+		return "", nil, fmt.Errorf("seek cursor has %d values for %d sort keys", len(p.values), len(p.keys))
+	}
+
+	// the operator for a key, which compares its value to the cursor's
+	opr := func(k SortKey) string {
+		if k.Desc != p.before {
+			return "<"
+		}
+		return ">"
+	}
+
+	sameDirection := true
+	for _, k := range p.keys[1:] {
+		sameDirection = sameDirection && k.Desc == p.keys[0].Desc
+	}
+
+	// like pagination, a placeholder format implies its dialect
+	d := rc.paginationDialect()
+	rowValues := d == nil || d.Supports(FeatureRowValues)
+
+	if len(p.keys) == 1 || (sameDirection && rowValues) {
+		cols := make([]string, len(p.keys))
+		for i, k := range p.keys {
+			cols[i] = k.Column
+		}
+		if len(p.keys) == 1 {
+			return fmt.Sprintf("%s %s ?", cols[0], opr(p.keys[0])), p.values, nil
+		}
+		sql := fmt.Sprintf("(%s) %s (%s)", strings.Join(cols, ", "), opr(p.keys[0]), Placeholders(len(cols)))
+		return sql, p.values, nil
+	}
+
+	// a > ? OR (a = ? AND b > ?) OR (a = ? AND b = ? AND c > ?)
+	var terms []string
+	var args []any
+	for i, k := range p.keys {
+		var conds []string
+		for j := 0; j < i; j++ {
+			conds = append(conds, p.keys[j].Column+" = ?")
+			args = append(args, p.values[j])
+		}
+		conds = append(conds, fmt.Sprintf("%s %s ?", k.Column, opr(k)))
+		args = append(args, p.values[i])
+
+		if len(conds) == 1 {
+			terms = append(terms, conds[0])
+		} else {
+			terms = append(terms, "("+strings.Join(conds, " AND ")+")")
+		}
+	}
+
+	return "(" + strings.Join(terms, " OR ") + ")", args, nil
+}
+
+// ErrInvalidCursor is returned by CursorCodec.Decode for a malformed or
+// tampered cursor.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorCodec encodes a Cursor into an opaque string which can be passed to
+// clients, signed with HMAC-SHA256 so that clients can't forge it.
+//
+// Cursor values may be nil, booleans, integers, floats, strings, []byte,
+// time.Time or driver.Valuers of those. Integers are decoded as int64 (or
+// uint64), and floats as float64.
+type CursorCodec struct {
+	key []byte
+}
+
+// minCursorKeySize is the minimum size of a CursorCodec key.
+const minCursorKeySize = 16
+
+// NewCursorCodec returns a CursorCodec which signs cursors with key, which must
+// be at least 16 random bytes, e.g. from crypto/rand.
+func NewCursorCodec(key []byte) (CursorCodec, error) {
+	if len(key) < minCursorKeySize {
+		return CursorCodec{}, fmt.Errorf("cursor key must be at least %d bytes, got %d", minCursorKeySize, len(key))
+	}
+	return CursorCodec{key: key}, nil
+}
+
+// cursorValue is the JSON encoding of a cursor value, tagged with its type so
+// that it decodes to the same type.
+type cursorValue struct {
+	T string          `json:"t"`
+	V json.RawMessage `json:"v,omitempty"`
+}
+
+// Encode encodes the cursor values.
+func (c CursorCodec) Encode(values ...any) (string, error) {
+	encoded := make([]cursorValue, len(values))
+	for i, v := range values {
+		var err error
+		if encoded[i], err = encodeCursorValue(v); err != nil {
+			return "", err
+		}
+	}
+
+	payload, err := json.Marshal(encoded)
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(c.sign(payload)), nil
+}
+
+// Decode decodes a cursor encoded by Encode, returning ErrInvalidCursor if it
+// is malformed or its signature doesn't match.
+func (c CursorCodec) Decode(s string) (Cursor, error) {
+	enc := base64.RawURLEncoding
+
+	payloadStr, sigStr, ok := strings.Cut(s, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := enc.DecodeString(payloadStr)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	sig, err := enc.DecodeString(sigStr)
+	if err != nil || !hmac.Equal(sig, c.sign(payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	var encoded []cursorValue
+	if err := json.Unmarshal(payload, &encoded); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := make(Cursor, len(encoded))
+	for i, v := range encoded {
+		if cursor[i], err = decodeCursorValue(v); err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+	return cursor, nil
+}
+
+func (c CursorCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func encodeCursorValue(v any) (cursorValue, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		var err error
+		if v, err = valuer.Value(); err != nil {
+			return cursorValue{}, err
+		}
+	}
+
+	var t string
+	switch x := v.(type) {
+	case nil:
+		return cursorValue{T: "null"}, nil
+	case bool:
+		t = "bool"
+	case int, int8, int16, int32, int64:
+		t = "int"
+	case uint, uint8, uint16, uint32, uint64:
+		t = "uint"
+	case float32, float64:
+		t = "float"
+	case string:
+		t = "string"
+	case []byte:
+		t = "bytes"
+	case time.Time:
+		t = "time"
+		v = x.Format(time.RFC3339Nano)
+	default:
+		return cursorValue{}, fmt.Errorf("cannot use %T as a cursor value", v)
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return cursorValue{}, err
+	}
+	return cursorValue{T: t, V: raw}, nil
+}
+
+func decodeCursorValue(v cursorValue) (any, error) {
+	var err error
+	switch v.T {
+	case "null":
+		return nil, nil
+	case "bool":
+		var x bool
+		err = json.Unmarshal(v.V, &x)
+		return x, err
+	case "int":
+		var x int64
+		err = json.Unmarshal(v.V, &x)
+		return x, err
+	case "uint":
+		var x uint64
+		err = json.Unmarshal(v.V, &x)
+		return x, err
+	case "float":
+		var x float64
+		err = json.Unmarshal(v.V, &x)
+		return x, err
+	case "string":
+		var x string
+		err = json.Unmarshal(v.V, &x)
+		return x, err
+	case "bytes":
+		var x []byte
+		err = json.Unmarshal(v.V, &x)
+		return x, err
+	case "time":
+		var s string
+		if err = json.Unmarshal(v.V, &s); err != nil {
+			return nil, err
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	}
+	return nil, fmt.Errorf("unknown cursor value type %q", v.T)
+}
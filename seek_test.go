@@ -0,0 +1,149 @@
+package sq
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectBuilder_SeekAfter(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	b := Select("id").From("events").Where(Eq{"org_id": 1})
+
+	tests := []struct {
+		name string
+		b    SelectBuilder
+		sql  string
+		args []any
+	}{
+		{
+			name: "first page",
+			b:    b.SeekAfter(nil, SortDesc("created_at"), SortDesc("id")).Limit(50),
+			sql:  "SELECT id FROM events WHERE org_id = ? ORDER BY created_at DESC, id DESC LIMIT 50",
+			args: []any{1},
+		},
+		{
+			name: "single key",
+			b:    b.SeekAfter(Cursor{10}, SortAsc("id")),
+			sql:  "SELECT id FROM events WHERE org_id = ? AND id > ? ORDER BY id ASC",
+			args: []any{1, 10},
+		},
+		{
+			name: "row values",
+			b:    b.SeekAfter(Cursor{created, 10}, SortDesc("created_at"), SortDesc("id")).Limit(50),
+			sql:  "SELECT id FROM events WHERE org_id = ? AND (created_at, id) < (?,?) ORDER BY created_at DESC, id DESC LIMIT 50",
+			args: []any{1, created, 10},
+		},
+		{
+			name: "mixed directions",
+			b:    b.SeekAfter(Cursor{"b", created, 10}, SortAsc("name"), SortDesc("created_at"), SortAsc("id")),
+			sql: "SELECT id FROM events WHERE org_id = ? AND " +
+				"(name > ? OR (name = ? AND created_at < ?) OR (name = ? AND created_at = ? AND id > ?)) " +
+				"ORDER BY name ASC, created_at DESC, id ASC",
+			args: []any{1, "b", "b", created, "b", created, 10},
+		},
+		{
+			name: "before",
+			b:    b.SeekBefore(Cursor{"b", 10}, SortAsc("name"), SortDesc("id")).Limit(10),
+			sql: "SELECT id FROM events WHERE org_id = ? AND " +
+				"(name < ? OR (name = ? AND id > ?)) " +
+				"ORDER BY name DESC, id ASC LIMIT 10",
+			args: []any{1, "b", "b", 10},
+		},
+		{
+			name: "before row values",
+			b:    b.SeekBefore(Cursor{created, 10}, SortAsc("created_at"), SortAsc("id")),
+			sql:  "SELECT id FROM events WHERE org_id = ? AND (created_at, id) < (?,?) ORDER BY created_at DESC, id DESC",
+			args: []any{1, created, 10},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sql, args, err := test.b.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.sql, sql)
+			require.Equal(t, test.args, args)
+		})
+	}
+}
+
+func TestSelectBuilder_SeekAfterDialect(t *testing.T) {
+	b := Select("id").From("events").SeekAfter(Cursor{"2024-05-01", 10}, SortAsc("day"), SortAsc("id"))
+
+	sql, _, err := b.Dialect(Postgres).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT id FROM events WHERE (day, id) > ($1,$2) ORDER BY day ASC, id ASC", sql)
+
+	sql, args, err := b.Dialect(SQLServer).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT id FROM events WHERE (day > @p1 OR (day = @p2 AND id > @p3)) ORDER BY day ASC, id ASC", sql)
+	require.Equal(t, []any{"2024-05-01", "2024-05-01", 10}, args)
+
+	// the placeholder format implies SQL Server
+	sql, _, err = b.PlaceholderFormat(AtP).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT id FROM events WHERE (day > @p1 OR (day = @p2 AND id > @p3)) ORDER BY day ASC, id ASC", sql)
+
+	_, _, err = Select("id").From("events").SeekAfter(Cursor{1}, SortAsc("day"), SortAsc("id")).ToSql()
+	require.EqualError(t, err, "seek cursor has 1 values for 2 sort keys")
+}
+
+func TestCursorCodec(t *testing.T) {
+	codec, err := NewCursorCodec([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+	created := time.Date(2024, 5, 1, 12, 0, 0, 123, time.FixedZone("", 3600))
+
+	s, err := codec.Encode(created, 42, uint8(7), 1.5, "a.b", []byte{1, 2}, true, nil, sql.NullString{String: "x", Valid: true})
+	require.NoError(t, err)
+	require.NotContains(t, s, "=")
+
+	cursor, err := codec.Decode(s)
+	require.NoError(t, err)
+	require.Len(t, cursor, 9)
+	require.True(t, created.Equal(cursor[0].(time.Time)))
+	require.Equal(t, Cursor{int64(42), uint64(7), 1.5, "a.b", []byte{1, 2}, true, nil, "x"}, cursor[1:])
+
+	// tampered payload
+	payload, sig, _ := strings.Cut(s, ".")
+	_, err = codec.Decode(payload[:len(payload)-2] + "AA." + sig)
+	require.ErrorIs(t, err, ErrInvalidCursor)
+
+	// other key
+	other, err := NewCursorCodec([]byte("fedcba9876543210"))
+	require.NoError(t, err)
+	_, err = other.Decode(s)
+	require.ErrorIs(t, err, ErrInvalidCursor)
+
+	for _, bad := range []string{"", "abc", "abc.def", "!!.!!"} {
+		_, err = codec.Decode(bad)
+		require.ErrorIs(t, err, ErrInvalidCursor)
+	}
+
+	_, err = codec.Encode(struct{}{})
+	require.EqualError(t, err, "cannot use struct {} as a cursor value")
+
+	for _, key := range [][]byte{nil, []byte("secret")} {
+		_, err = NewCursorCodec(key)
+		require.EqualError(t, err, fmt.Sprintf("cursor key must be at least 16 bytes, got %d", len(key)))
+	}
+}
+
+func TestCursorCodec_SeekAfter(t *testing.T) {
+	codec, err := NewCursorCodec([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+
+	s, err := codec.Encode("2024-05-01", 10)
+	require.NoError(t, err)
+
+	cursor, err := codec.Decode(s)
+	require.NoError(t, err)
+
+	_, args, err := Select("id").From("events").SeekAfter(cursor, SortAsc("day"), SortAsc("id")).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, []any{"2024-05-01", int64(10)}, args)
+}
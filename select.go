@@ -1,6 +1,8 @@
 package sq
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"strings"
 
@@ -9,7 +11,10 @@ import (
 
 type selectData struct {
 	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
+	RunWith           Runner
 	Prefixes          []Sqlizer
+	WithParts         []withPart
 	Options           []string
 	Columns           []Sqlizer
 	From              Sqlizer
@@ -17,10 +22,35 @@ type selectData struct {
 	WhereParts        []Sqlizer
 	GroupBys          []string
 	HavingParts       []Sqlizer
+	Windows           []Sqlizer
 	OrderByParts      []Sqlizer
 	Limit             string
 	Offset            string
 	Suffixes          []Sqlizer
+	Lock              LockStrength
+	LockOf            []string
+	LockWait          string
+}
+
+func (d *selectData) ExecContext(ctx context.Context) (sql.Result, error) {
+	if d.RunWith == nil {
+		return nil, ErrRunnerNotSet
+	}
+	return ExecContextWith(ctx, d.RunWith, d)
+}
+
+func (d *selectData) QueryContext(ctx context.Context) (*sql.Rows, error) {
+	if d.RunWith == nil {
+		return nil, ErrRunnerNotSet
+	}
+	return QueryContextWith(ctx, d.RunWith, d)
+}
+
+func (d *selectData) QueryRowContext(ctx context.Context) RowScanner {
+	if d.RunWith == nil {
+		return &Row{err: ErrRunnerNotSet}
+	}
+	return QueryRowContextWith(ctx, d.RunWith, d)
 }
 
 func (d *selectData) ToSql() (sqlStr string, args []any, err error) {
@@ -29,11 +59,15 @@ func (d *selectData) ToSql() (sqlStr string, args []any, err error) {
 		return
 	}
 
-	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sqlStr)
+	sqlStr, args, err = replacePlaceholders(d.PlaceholderFormat, sqlStr, args)
 	return
 }
 
 func (d *selectData) ToSqlRaw() (sqlStr string, args []any, err error) {
+	return d.toSqlRaw(renderContext{dialect: d.Dialect, format: d.PlaceholderFormat})
+}
+
+func (d *selectData) toSqlRaw(rc renderContext) (sqlStr string, args []any, err error) {
 	if len(d.Columns) == 0 {
 		err = fmt.Errorf("select statements must have at least one result column")
 		return
@@ -42,7 +76,7 @@ func (d *selectData) ToSqlRaw() (sqlStr string, args []any, err error) {
 	sql := &strings.Builder{}
 
 	if len(d.Prefixes) > 0 {
-		args, err = appendToSql(d.Prefixes, sql, " ", args)
+		args, err = appendToSql(rc, d.Prefixes, sql, " ", args)
 		if err != nil {
 			return
 		}
@@ -50,6 +84,18 @@ func (d *selectData) ToSqlRaw() (sqlStr string, args []any, err error) {
 		sql.WriteString(" ")
 	}
 
+	if len(d.WithParts) > 0 {
+		args, err = appendWithToSql(rc, d.WithParts, sql, args)
+		if err != nil {
+			return
+		}
+	}
+
+	top, limit, err := rc.selectLimit(d.Limit, d.Offset, len(d.OrderByParts) > 0)
+	if err != nil {
+		return
+	}
+
 	sql.WriteString("SELECT ")
 
 	if len(d.Options) > 0 {
@@ -57,24 +103,37 @@ func (d *selectData) ToSqlRaw() (sqlStr string, args []any, err error) {
 		sql.WriteString(" ")
 	}
 
+	sql.WriteString(top)
+
 	if len(d.Columns) > 0 {
-		args, err = appendToSql(d.Columns, sql, ", ", args)
+		args, err = appendToSql(rc, d.Columns, sql, ", ", args)
 		if err != nil {
 			return
 		}
 	}
 
+	lockHint, lock, err := rc.lockClause(d.Lock, d.LockOf, d.LockWait, len(d.Limit) > 0 || len(d.Offset) > 0)
+	if err != nil {
+		return
+	}
+
 	if d.From != nil {
 		sql.WriteString(" FROM ")
-		args, err = appendToSql([]Sqlizer{d.From}, sql, "", args)
+		args, err = appendToSql(rc, []Sqlizer{d.From}, sql, "", args)
 		if err != nil {
 			return
 		}
+		sql.WriteString(lockHint)
+	} else if len(lockHint) > 0 {
+		err = fmt.Errorf("%s require a From table", FeatureLockHints)
+		return
+	} else if rc.dialect != nil && !rc.dialect.Supports(FeatureSelectNoFrom) {
+		sql.WriteString(" FROM DUAL")
 	}
 
 	if len(d.Joins) > 0 {
 		sql.WriteString(" ")
-		args, err = appendToSql(d.Joins, sql, " ", args)
+		args, err = appendToSql(rc, d.Joins, sql, " ", args)
 		if err != nil {
 			return
 		}
@@ -82,7 +141,7 @@ func (d *selectData) ToSqlRaw() (sqlStr string, args []any, err error) {
 
 	if len(d.WhereParts) > 0 {
 		sql.WriteString(" WHERE ")
-		args, err = appendToSql(d.WhereParts, sql, " AND ", args)
+		args, err = appendToSql(rc, d.WhereParts, sql, " AND ", args)
 		if err != nil {
 			return
 		}
@@ -95,34 +154,35 @@ func (d *selectData) ToSqlRaw() (sqlStr string, args []any, err error) {
 
 	if len(d.HavingParts) > 0 {
 		sql.WriteString(" HAVING ")
-		args, err = appendToSql(d.HavingParts, sql, " AND ", args)
+		args, err = appendToSql(rc, d.HavingParts, sql, " AND ", args)
 		if err != nil {
 			return
 		}
 	}
 
-	if len(d.OrderByParts) > 0 {
-		sql.WriteString(" ORDER BY ")
-		args, err = appendToSql(d.OrderByParts, sql, ", ", args)
+	if len(d.Windows) > 0 {
+		sql.WriteString(" WINDOW ")
+		args, err = appendToSql(rc, d.Windows, sql, ", ", args)
 		if err != nil {
 			return
 		}
 	}
 
-	if len(d.Limit) > 0 {
-		sql.WriteString(" LIMIT ")
-		sql.WriteString(d.Limit)
+	if len(d.OrderByParts) > 0 {
+		sql.WriteString(" ORDER BY ")
+		args, err = appendToSql(rc, d.OrderByParts, sql, ", ", args)
+		if err != nil {
+			return
+		}
 	}
 
-	if len(d.Offset) > 0 {
-		sql.WriteString(" OFFSET ")
-		sql.WriteString(d.Offset)
-	}
+	sql.WriteString(limit)
+	sql.WriteString(lock)
 
 	if len(d.Suffixes) > 0 {
 		sql.WriteString(" ")
 
-		args, err = appendToSql(d.Suffixes, sql, " ", args)
+		args, err = appendToSql(rc, d.Suffixes, sql, " ", args)
 		if err != nil {
 			return
 		}
@@ -149,6 +209,45 @@ func (b SelectBuilder) PlaceholderFormat(f PlaceholderFormat) SelectBuilder {
 	return builder.Set(b, "PlaceholderFormat", f).(SelectBuilder)
 }
 
+// Dialect sets the Dialect (e.g. Postgres or MySQL) for the query, and sets the
+// PlaceholderFormat to the dialect's default. A nil Dialect clears it, keeping
+// the PlaceholderFormat.
+func (b SelectBuilder) Dialect(d Dialect) SelectBuilder {
+	if d == nil {
+		return builder.Delete(b, "Dialect").(SelectBuilder)
+	}
+	b = builder.Set(b, "Dialect", d).(SelectBuilder)
+	return b.PlaceholderFormat(d.PlaceholderFormat())
+}
+
+// Runner methods
+
+// RunWith sets a Runner (like database/sql.DB) to be used with e.g.
+// ExecContext.
+func (b SelectBuilder) RunWith(runner Runner) SelectBuilder {
+	return builder.Set(b, "RunWith", runner).(SelectBuilder)
+}
+
+// ExecContext builds and ExecContexts the query with the Runner set by RunWith.
+func (b SelectBuilder) ExecContext(ctx context.Context) (sql.Result, error) {
+	data := builder.GetStruct(b).(selectData)
+	return data.ExecContext(ctx)
+}
+
+// QueryContext builds and QueryContexts the query with the Runner set by
+// RunWith.
+func (b SelectBuilder) QueryContext(ctx context.Context) (*sql.Rows, error) {
+	data := builder.GetStruct(b).(selectData)
+	return data.QueryContext(ctx)
+}
+
+// QueryRowContext builds and QueryRowContexts the query with the Runner set by
+// RunWith.
+func (b SelectBuilder) QueryRowContext(ctx context.Context) RowScanner {
+	data := builder.GetStruct(b).(selectData)
+	return data.QueryRowContext(ctx)
+}
+
 // SQL methods
 
 // ToSql builds the query into a SQL string and bound args.
@@ -162,6 +261,36 @@ func (b SelectBuilder) ToSqlRaw() (string, []any, error) {
 	return data.ToSqlRaw()
 }
 
+// toSqlContext builds a nested query, inheriting the Dialect of the enclosing
+// statement if none is set.
+func (b SelectBuilder) toSqlContext(rc renderContext) (string, []any, error) {
+	data := builder.GetStruct(b).(selectData)
+	if data.Dialect != nil {
+		rc.dialect = data.Dialect
+	}
+	return data.toSqlRaw(rc)
+}
+
+// With adds a common table expression to the WITH clause of the query.
+//
+// See WithBuilder.As.
+func (b SelectBuilder) With(alias string, sql Sqlizer, opts ...CTEOption) SelectBuilder {
+	return builder.Append(b, "WithParts", newWithPart(alias, sql, opts)).(SelectBuilder)
+}
+
+// WithRecursive adds a recursive common table expression to the WITH clause
+// of the query.
+//
+// See WithBuilder.Recursive.
+func (b SelectBuilder) WithRecursive(alias string, anchor, recursive SelectBuilder, opts ...CTEOption) SelectBuilder {
+	return builder.Append(b, "WithParts", newRecursiveWithPart(alias, anchor, recursive, opts)).(SelectBuilder)
+}
+
+// RemoveWith removes the WITH clause.
+func (b SelectBuilder) RemoveWith() SelectBuilder {
+	return builder.Delete(b, "WithParts").(SelectBuilder)
+}
+
 // Prefix adds an expression to the beginning of the query
 func (b SelectBuilder) Prefix(sql string, args ...any) SelectBuilder {
 	return b.PrefixExpr(Expr(sql, args...))
@@ -208,6 +337,10 @@ func (b SelectBuilder) RemoveColumns() SelectBuilder {
 // the columns string, for example:
 //
 //	Column("IF(col IN ("+sq.Placeholders(3)+"), 1, 0) as col", 1, 2, 3)
+//
+// or, with a single Named argument, to named parameters (see NamedExpr):
+//
+//	Column("COALESCE(nickname, :default) AS name", sq.Named{"default": "anon"})
 func (b SelectBuilder) Column(column any, args ...any) SelectBuilder {
 	return builder.Append(b, "Columns", newPart(column, args...)).(SelectBuilder)
 }
@@ -217,9 +350,10 @@ func (b SelectBuilder) From(from string) SelectBuilder {
 	return builder.Set(b, "From", newPart(from)).(SelectBuilder)
 }
 
-// FromSelect sets a subquery into the FROM clause of the query.
+// FromSelect sets a subquery into the FROM clause of the query. The alias
+// follows AS, except for dialects which don't accept it, such as Oracle.
 func (b SelectBuilder) FromSelect(from Sqlizer, alias string) SelectBuilder {
-	return builder.Set(b, "From", Alias(from, alias)).(SelectBuilder)
+	return builder.Set(b, "From", derivedTable(from, alias)).(SelectBuilder)
 }
 
 // JoinClause adds a join clause to the query.
@@ -299,32 +433,32 @@ func (b SelectBuilder) FullJoinOn(join any, on Sqlizer) SelectBuilder {
 
 // JoinSelect adds a JOIN ON clause to the query.
 func (b SelectBuilder) JoinSelect(join SelectBuilder, alias string, on Sqlizer) SelectBuilder {
-	return b.JoinOn(Alias(join.PlaceholderFormat(Question), alias), on)
+	return b.JoinOn(derivedTable(join.PlaceholderFormat(Question), alias), on)
 }
 
 // LeftJoinSelect adds a LEFT JOIN ON clause to the query.
 func (b SelectBuilder) LeftJoinSelect(join SelectBuilder, alias string, on Sqlizer) SelectBuilder {
-	return b.LeftJoinOn(Alias(join.PlaceholderFormat(Question), alias), on)
+	return b.LeftJoinOn(derivedTable(join.PlaceholderFormat(Question), alias), on)
 }
 
 // RightJoinSelect adds a RIGHT JOIN ON clause to the query.
 func (b SelectBuilder) RightJoinSelect(join SelectBuilder, alias string, on Sqlizer) SelectBuilder {
-	return b.RightJoinOn(Alias(join.PlaceholderFormat(Question), alias), on)
+	return b.RightJoinOn(derivedTable(join.PlaceholderFormat(Question), alias), on)
 }
 
 // InnerJoinSelect adds a INNER JOIN ON clause to the query.
 func (b SelectBuilder) InnerJoinSelect(join SelectBuilder, alias string, on Sqlizer) SelectBuilder {
-	return b.InnerJoinOn(Alias(join.PlaceholderFormat(Question), alias), on)
+	return b.InnerJoinOn(derivedTable(join.PlaceholderFormat(Question), alias), on)
 }
 
 // CrossJoinSelect adds a CROSS JOIN ON clause to the query.
 func (b SelectBuilder) CrossJoinSelect(join SelectBuilder, alias string, on Sqlizer) SelectBuilder {
-	return b.CrossJoinOn(Alias(join.PlaceholderFormat(Question), alias), on)
+	return b.CrossJoinOn(derivedTable(join.PlaceholderFormat(Question), alias), on)
 }
 
 // FullJoinSelect adds a FULL JOIN ON clause to the query.
 func (b SelectBuilder) FullJoinSelect(join SelectBuilder, alias string, on Sqlizer) SelectBuilder {
-	return b.FullJoinOn(Alias(join.PlaceholderFormat(Question), alias), on)
+	return b.FullJoinOn(derivedTable(join.PlaceholderFormat(Question), alias), on)
 }
 
 // Where adds an expression to the WHERE clause of the query.
@@ -337,7 +471,8 @@ func (b SelectBuilder) FullJoinSelect(join SelectBuilder, alias string, on Sqliz
 //
 // string - SQL expression.
 // If the expression has SQL placeholders then a set of arguments must be passed
-// as well, one for each placeholder.
+// as well, one for each placeholder. If the only argument is Named, the
+// expression uses :name or @name parameters instead (see NamedExpr).
 //
 // map[string]interface{} OR Eq - map of SQL expressions to values. Each key is
 // transformed into an expression like "<key> = ?", with the corresponding value
@@ -381,6 +516,21 @@ func (b SelectBuilder) RemoveHaving() SelectBuilder {
 	return builder.Delete(b, "HavingParts").(SelectBuilder)
 }
 
+// Window adds a named window to the WINDOW clause of the query, which window
+// functions can refer to with WindowFunc.OverWindow or WindowSpec.Base.
+//
+// Ex:
+//
+//	Select("id").
+//		Column(Rank().OverWindow("w").As("rank")).
+//		From("scores").
+//		Window("w", Over().PartitionBy("game_id").OrderBy("score DESC"))
+//	// SELECT id, RANK() OVER w AS rank FROM scores
+//	// WINDOW w AS (PARTITION BY game_id ORDER BY score DESC)
+func (b SelectBuilder) Window(name string, spec WindowSpec) SelectBuilder {
+	return builder.Append(b, "Windows", namedWindow{name: name, spec: spec}).(SelectBuilder)
+}
+
 // OrderByClause adds ORDER BY clause to the query.
 func (b SelectBuilder) OrderByClause(pred any, args ...any) SelectBuilder {
 	return builder.Append(b, "OrderByParts", newPart(pred, args...)).(SelectBuilder)
@@ -401,6 +551,9 @@ func (b SelectBuilder) RemoveOrderBy() SelectBuilder {
 }
 
 // Limit sets a LIMIT clause on the query.
+//
+// Depending on the Dialect (or, if none is set, the PlaceholderFormat), the
+// limit is rendered as LIMIT n, TOP n or FETCH FIRST n ROWS ONLY.
 func (b SelectBuilder) Limit(limit uint64) SelectBuilder {
 	return builder.Set(b, "Limit", fmt.Sprintf("%d", limit)).(SelectBuilder)
 }
@@ -411,6 +564,9 @@ func (b SelectBuilder) RemoveLimit() SelectBuilder {
 }
 
 // Offset sets a OFFSET clause on the query.
+//
+// Depending on the Dialect (or, if none is set, the PlaceholderFormat), the
+// offset is rendered as OFFSET n or OFFSET n ROWS, which requires ORDER BY.
 func (b SelectBuilder) Offset(offset uint64) SelectBuilder {
 	return builder.Set(b, "Offset", fmt.Sprintf("%d", offset)).(SelectBuilder)
 }
@@ -420,6 +576,44 @@ func (b SelectBuilder) RemoveOffset() SelectBuilder {
 	return builder.Delete(b, "Offset").(SelectBuilder)
 }
 
+// For sets a row locking clause on the query, e.g. FOR UPDATE, which is
+// rendered after LIMIT and OFFSET. For SQL Server, it is rendered as table
+// hints on the From table instead, e.g. WITH (UPDLOCK, READPAST), which only
+// exist for LockUpdate.
+//
+// Ex:
+//
+//	Select("id").From("jobs").Where(Eq{"state": "queued"}).Limit(1).
+//		For(LockUpdate).SkipLocked()
+//	// SELECT id FROM jobs WHERE state = ? LIMIT 1 FOR UPDATE SKIP LOCKED
+func (b SelectBuilder) For(strength LockStrength) SelectBuilder {
+	return builder.Set(b, "Lock", strength).(SelectBuilder)
+}
+
+// Of limits the row locking clause set by For to the given tables.
+func (b SelectBuilder) Of(tables ...string) SelectBuilder {
+	return builder.Extend(b, "LockOf", tables).(SelectBuilder)
+}
+
+// NoWait makes the row locking clause set by For fail rather than wait for
+// locked rows.
+func (b SelectBuilder) NoWait() SelectBuilder {
+	return builder.Set(b, "LockWait", lockNoWait).(SelectBuilder)
+}
+
+// SkipLocked makes the row locking clause set by For skip locked rows rather
+// than wait for them.
+func (b SelectBuilder) SkipLocked() SelectBuilder {
+	return builder.Set(b, "LockWait", lockSkipLocked).(SelectBuilder)
+}
+
+// RemoveFor removes the row locking clause.
+func (b SelectBuilder) RemoveFor() SelectBuilder {
+	b = builder.Delete(b, "Lock").(SelectBuilder)
+	b = builder.Delete(b, "LockOf").(SelectBuilder)
+	return builder.Delete(b, "LockWait").(SelectBuilder)
+}
+
 // Suffix adds an expression to the end of the query
 func (b SelectBuilder) Suffix(sql string, args ...any) SelectBuilder {
 	return b.SuffixExpr(Expr(sql, args...))
@@ -434,3 +628,44 @@ func (b SelectBuilder) RemoveSuffixes() SelectBuilder {
 func (b SelectBuilder) SuffixExpr(expr Sqlizer) SelectBuilder {
 	return builder.Append(b, "Suffixes", expr).(SelectBuilder)
 }
+
+// compound starts a CompoundBuilder with the query as its first part, which
+// inherits the query's PlaceholderFormat, Dialect and Runner.
+func (b SelectBuilder) compound() CompoundBuilder {
+	data := builder.GetStruct(b).(selectData)
+
+	c := CompoundBuilder(builder.EmptyBuilder).PlaceholderFormat(data.PlaceholderFormat)
+	if data.Dialect != nil {
+		c = builder.Set(c, "Dialect", data.Dialect).(CompoundBuilder)
+	}
+	if data.RunWith != nil {
+		c = c.RunWith(data.RunWith)
+	}
+
+	return builder.Append(c, "Parts", compoundPart{query: b}).(CompoundBuilder)
+}
+
+// Union combines the query with others using UNION.
+//
+// Ex:
+//
+//	Select("id").From("users").Union(Select("id").From("admins")).OrderBy("id")
+//	// SELECT id FROM users UNION SELECT id FROM admins ORDER BY id
+func (b SelectBuilder) Union(others ...Sqlizer) CompoundBuilder {
+	return b.compound().Union(others...)
+}
+
+// UnionAll combines the query with others using UNION ALL. See Union.
+func (b SelectBuilder) UnionAll(others ...Sqlizer) CompoundBuilder {
+	return b.compound().UnionAll(others...)
+}
+
+// Intersect combines the query with others using INTERSECT. See Union.
+func (b SelectBuilder) Intersect(others ...Sqlizer) CompoundBuilder {
+	return b.compound().Intersect(others...)
+}
+
+// Except combines the query with others using EXCEPT. See Union.
+func (b SelectBuilder) Except(others ...Sqlizer) CompoundBuilder {
+	return b.compound().Except(others...)
+}
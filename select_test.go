@@ -444,3 +444,13 @@ func TestRemoveColumns(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "SELECT name FROM users", sql)
 }
+
+func TestSelectBuilderFromDual(t *testing.T) {
+	sql, _, err := Select("1").Dialect(Oracle).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT 1 FROM DUAL", sql)
+
+	sql, _, err = Select("1").Dialect(Postgres).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT 1", sql)
+}
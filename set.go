@@ -1,71 +1,376 @@
 package sq
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"strings"
+
+	"github.com/userhubdev/sq/internal/builder"
 )
 
-type setOp struct {
-	parts []Sqlizer
-	sep   string
+// compoundPart is a query of a compound query, with the set operator which
+// joins it to the preceding queries.
+type compoundPart struct {
+	op    string
+	query Sqlizer
+}
+
+type compoundData struct {
+	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
+	RunWith           Runner
+	Operator          string
+	Parts             []compoundPart
+	OrderByParts      []Sqlizer
+	Limit             string
+	Offset            string
+}
+
+func (d *compoundData) ExecContext(ctx context.Context) (sql.Result, error) {
+	if d.RunWith == nil {
+		return nil, ErrRunnerNotSet
+	}
+	return ExecContextWith(ctx, d.RunWith, d)
+}
+
+func (d *compoundData) QueryContext(ctx context.Context) (*sql.Rows, error) {
+	if d.RunWith == nil {
+		return nil, ErrRunnerNotSet
+	}
+	return QueryContextWith(ctx, d.RunWith, d)
+}
+
+func (d *compoundData) QueryRowContext(ctx context.Context) RowScanner {
+	if d.RunWith == nil {
+		return &Row{err: ErrRunnerNotSet}
+	}
+	return QueryRowContextWith(ctx, d.RunWith, d)
+}
+
+func (d *compoundData) ToSql() (sqlStr string, args []any, err error) {
+	sqlStr, args, err = d.ToSqlRaw()
+	if err != nil {
+		return
+	}
+
+	sqlStr, args, err = replacePlaceholders(d.PlaceholderFormat, sqlStr, args)
+	return
+}
+
+func (d *compoundData) ToSqlRaw() (sqlStr string, args []any, err error) {
+	return d.toSqlRaw(renderContext{dialect: d.Dialect, format: d.PlaceholderFormat})
 }
 
-func (op setOp) ToSql() (string, []any, error) {
-	if len(op.parts) == 0 {
-		return "", nil, fmt.Errorf("%s has no parts", op.sep)
+func (d *compoundData) toSqlRaw(rc renderContext) (sqlStr string, args []any, err error) {
+	if len(d.Parts) == 0 {
+		err = fmt.Errorf("%s has no parts", d.Operator)
+		return
 	}
 
-	b := Builder{}
+	ordered := len(d.OrderByParts) > 0
+	top, limit, err := rc.selectLimit(d.Limit, d.Offset, ordered)
+	if err != nil {
+		return
+	}
+	if len(top) > 0 {
+		// TOP can't limit a compound query, so fall back to OFFSET ... FETCH
+		if !ordered {
+			err = fmt.Errorf("LIMIT requires ORDER BY on %s", rc.paginationDialect().Name())
+			return
+		}
+		_, limit, err = rc.selectLimit(d.Limit, "0", ordered)
+		if err != nil {
+			return
+		}
+	}
+
+	sql := &strings.Builder{}
 
-	for i, p := range op.parts {
+	for i, p := range d.Parts {
 		if i > 0 {
-			b.WriteString(" ")
-			b.WriteString(op.sep)
+			if i > 1 && p.op != d.Parts[i-1].op {
+				// evaluate the operators left to right, regardless of their
+				// precedence, e.g. INTERSECT binding tighter than UNION
+				if err = rc.require(FeatureCompoundParens); err != nil {
+					return
+				}
+				prev := sql.String()
+				sql.Reset()
+				sql.WriteString("(")
+				sql.WriteString(prev)
+				sql.WriteString(")")
+			}
+			sql.WriteString(" ")
+			sql.WriteString(p.op)
+			sql.WriteString(" ")
 		}
 
-		b.WriteSql(p)
+		var partSql string
+		var partArgs []any
+		partSql, partArgs, err = nestedToSql(rc, p.query)
+		if err != nil {
+			return
+		}
+
+		if needsCompoundParens(p.query) {
+			if err = rc.require(FeatureCompoundParens); err != nil {
+				return
+			}
+			partSql = "(" + partSql + ")"
+		}
+
+		sql.WriteString(partSql)
+		args = append(args, partArgs...)
+	}
+
+	if ordered {
+		sql.WriteString(" ORDER BY ")
+		args, err = appendToSql(rc, d.OrderByParts, sql, ", ", args)
+		if err != nil {
+			return
+		}
 	}
 
-	return b.ToSql()
+	sql.WriteString(limit)
+
+	sqlStr = sql.String()
+	return
 }
 
-func UnionAll(parts ...Sqlizer) Sqlizer {
-	return setOp{
-		parts: parts,
-		sep:   "UNION ALL",
+// needsCompoundParens reports whether a part of a compound query must be
+// parenthesized: a compound query, or a query with its own ORDER BY, LIMIT or
+// OFFSET.
+func needsCompoundParens(query Sqlizer) bool {
+	switch q := query.(type) {
+	case CompoundBuilder:
+		return true
+	case SelectBuilder:
+		data := builder.GetStruct(q).(selectData)
+		return len(data.OrderByParts) > 0 || len(data.Limit) > 0 || len(data.Offset) > 0
 	}
+	return false
 }
 
-func UnionDistinct(parts ...Sqlizer) Sqlizer {
-	return setOp{
-		parts: parts,
-		sep:   "UNION DISTINCT",
-	}
+// Builder
+
+// CompoundBuilder builds compound queries, which combine the results of
+// queries with set operators such as UNION ALL.
+type CompoundBuilder builder.Builder
+
+func init() {
+	builder.Register(CompoundBuilder{}, compoundData{})
 }
 
-func IntersectAll(parts ...Sqlizer) Sqlizer {
-	return setOp{
-		parts: parts,
-		sep:   "INTERSECT ALL",
+func newCompound(b CompoundBuilder, op string, parts []Sqlizer) CompoundBuilder {
+	b = builder.Set(b, "Operator", op).(CompoundBuilder)
+	for _, p := range parts {
+		b = builder.Append(b, "Parts", compoundPart{op: op, query: p}).(CompoundBuilder)
 	}
+	return b
+}
+
+// UnionAll returns a compound query of parts joined with UNION ALL.
+//
+// Ex:
+//
+//	UnionAll(Select("id").From("users"), Select("id").From("admins")).
+//		Except(Select("id").From("banned")).
+//		OrderBy("id")
+//	// (SELECT id FROM users UNION ALL SELECT id FROM admins) EXCEPT SELECT id FROM banned ORDER BY id
+func UnionAll(parts ...Sqlizer) CompoundBuilder {
+	return CompoundBuilder(StatementBuilder).UnionAll(parts...)
+}
+
+// UnionDistinct returns a compound query of parts joined with UNION DISTINCT.
+func UnionDistinct(parts ...Sqlizer) CompoundBuilder {
+	return CompoundBuilder(StatementBuilder).UnionDistinct(parts...)
+}
+
+// IntersectAll returns a compound query of parts joined with INTERSECT ALL.
+func IntersectAll(parts ...Sqlizer) CompoundBuilder {
+	return CompoundBuilder(StatementBuilder).IntersectAll(parts...)
+}
+
+// IntersectDistinct returns a compound query of parts joined with INTERSECT
+// DISTINCT.
+func IntersectDistinct(parts ...Sqlizer) CompoundBuilder {
+	return CompoundBuilder(StatementBuilder).IntersectDistinct(parts...)
+}
+
+// ExceptAll returns a compound query of parts joined with EXCEPT ALL.
+func ExceptAll(parts ...Sqlizer) CompoundBuilder {
+	return CompoundBuilder(StatementBuilder).ExceptAll(parts...)
+}
+
+// ExceptDistinct returns a compound query of parts joined with EXCEPT
+// DISTINCT.
+func ExceptDistinct(parts ...Sqlizer) CompoundBuilder {
+	return CompoundBuilder(StatementBuilder).ExceptDistinct(parts...)
+}
+
+// Format methods
+
+// PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the
+// query.
+func (b CompoundBuilder) PlaceholderFormat(f PlaceholderFormat) CompoundBuilder {
+	return builder.Set(b, "PlaceholderFormat", f).(CompoundBuilder)
 }
 
-func IntersectDistinct(parts ...Sqlizer) Sqlizer {
-	return setOp{
-		parts: parts,
-		sep:   "INTERSECT DISTINCT",
+// Dialect sets the Dialect (e.g. Postgres or MySQL) for the query, and sets the
+// PlaceholderFormat to the dialect's default. A nil Dialect clears it, keeping
+// the PlaceholderFormat.
+func (b CompoundBuilder) Dialect(d Dialect) CompoundBuilder {
+	if d == nil {
+		return builder.Delete(b, "Dialect").(CompoundBuilder)
 	}
+	b = builder.Set(b, "Dialect", d).(CompoundBuilder)
+	return b.PlaceholderFormat(d.PlaceholderFormat())
+}
+
+// Runner methods
+
+// RunWith sets a Runner (like database/sql.DB) to be used with e.g.
+// ExecContext.
+func (b CompoundBuilder) RunWith(runner Runner) CompoundBuilder {
+	return builder.Set(b, "RunWith", runner).(CompoundBuilder)
+}
+
+// ExecContext builds and ExecContexts the query with the Runner set by RunWith.
+func (b CompoundBuilder) ExecContext(ctx context.Context) (sql.Result, error) {
+	data := builder.GetStruct(b).(compoundData)
+	return data.ExecContext(ctx)
+}
+
+// QueryContext builds and QueryContexts the query with the Runner set by
+// RunWith.
+func (b CompoundBuilder) QueryContext(ctx context.Context) (*sql.Rows, error) {
+	data := builder.GetStruct(b).(compoundData)
+	return data.QueryContext(ctx)
+}
+
+// QueryRowContext builds and QueryRowContexts the query with the Runner set by
+// RunWith.
+func (b CompoundBuilder) QueryRowContext(ctx context.Context) RowScanner {
+	data := builder.GetStruct(b).(compoundData)
+	return data.QueryRowContext(ctx)
+}
+
+// SQL methods
+
+// ToSql builds the query into a SQL string and bound args.
+func (b CompoundBuilder) ToSql() (string, []any, error) {
+	data := builder.GetStruct(b).(compoundData)
+	return data.ToSql()
+}
+
+func (b CompoundBuilder) ToSqlRaw() (string, []any, error) {
+	data := builder.GetStruct(b).(compoundData)
+	return data.ToSqlRaw()
 }
 
-func ExceptAll(parts ...Sqlizer) Sqlizer {
-	return setOp{
-		parts: parts,
-		sep:   "EXCEPT ALL",
+// toSqlContext builds a nested query, inheriting the Dialect of the enclosing
+// statement if none is set.
+func (b CompoundBuilder) toSqlContext(rc renderContext) (string, []any, error) {
+	data := builder.GetStruct(b).(compoundData)
+	if data.Dialect != nil {
+		rc.dialect = data.Dialect
 	}
+	return data.toSqlRaw(rc)
+}
+
+// Union adds parts to the query with UNION.
+//
+// The queries before a change of operator are parenthesized, so that the
+// operators are applied in the order they were added. Compound queries and
+// queries with their own ORDER BY, LIMIT or OFFSET are parenthesized too. It is
+// an error to need parentheses with dialects which reject them, e.g. SQLite.
+func (b CompoundBuilder) Union(parts ...Sqlizer) CompoundBuilder {
+	return newCompound(b, "UNION", parts)
+}
+
+// UnionAll adds parts to the query with UNION ALL. See Union.
+func (b CompoundBuilder) UnionAll(parts ...Sqlizer) CompoundBuilder {
+	return newCompound(b, "UNION ALL", parts)
+}
+
+// UnionDistinct adds parts to the query with UNION DISTINCT. See Union.
+func (b CompoundBuilder) UnionDistinct(parts ...Sqlizer) CompoundBuilder {
+	return newCompound(b, "UNION DISTINCT", parts)
+}
+
+// Intersect adds parts to the query with INTERSECT. See Union.
+func (b CompoundBuilder) Intersect(parts ...Sqlizer) CompoundBuilder {
+	return newCompound(b, "INTERSECT", parts)
 }
 
-func ExceptDistinct(parts ...Sqlizer) Sqlizer {
-	return setOp{
-		parts: parts,
-		sep:   "EXCEPT DISTINCT",
+// IntersectAll adds parts to the query with INTERSECT ALL. See Union.
+func (b CompoundBuilder) IntersectAll(parts ...Sqlizer) CompoundBuilder {
+	return newCompound(b, "INTERSECT ALL", parts)
+}
+
+// IntersectDistinct adds parts to the query with INTERSECT DISTINCT. See
+// Union.
+func (b CompoundBuilder) IntersectDistinct(parts ...Sqlizer) CompoundBuilder {
+	return newCompound(b, "INTERSECT DISTINCT", parts)
+}
+
+// Except adds parts to the query with EXCEPT. See Union.
+func (b CompoundBuilder) Except(parts ...Sqlizer) CompoundBuilder {
+	return newCompound(b, "EXCEPT", parts)
+}
+
+// ExceptAll adds parts to the query with EXCEPT ALL. See Union.
+func (b CompoundBuilder) ExceptAll(parts ...Sqlizer) CompoundBuilder {
+	return newCompound(b, "EXCEPT ALL", parts)
+}
+
+// ExceptDistinct adds parts to the query with EXCEPT DISTINCT. See Union.
+func (b CompoundBuilder) ExceptDistinct(parts ...Sqlizer) CompoundBuilder {
+	return newCompound(b, "EXCEPT DISTINCT", parts)
+}
+
+// OrderByClause adds ORDER BY clause to the query, which orders the combined
+// result.
+func (b CompoundBuilder) OrderByClause(pred any, args ...any) CompoundBuilder {
+	return builder.Append(b, "OrderByParts", newPart(pred, args...)).(CompoundBuilder)
+}
+
+// OrderBy adds ORDER BY expressions to the query, which order the combined
+// result.
+func (b CompoundBuilder) OrderBy(orderBys ...string) CompoundBuilder {
+	for _, orderBy := range orderBys {
+		b = b.OrderByClause(orderBy)
 	}
+
+	return b
+}
+
+// RemoveOrderBy removes ORDER BY clause.
+func (b CompoundBuilder) RemoveOrderBy() CompoundBuilder {
+	return builder.Delete(b, "OrderByParts").(CompoundBuilder)
+}
+
+// Limit sets a LIMIT clause on the query.
+//
+// See SelectBuilder.Limit for how it is rendered for each Dialect. A limit
+// without an offset on SQL Server is rendered with OFFSET 0 ROWS, as TOP
+// can't be applied to a compound query.
+func (b CompoundBuilder) Limit(limit uint64) CompoundBuilder {
+	return builder.Set(b, "Limit", fmt.Sprintf("%d", limit)).(CompoundBuilder)
+}
+
+// RemoveLimit removes LIMIT clause.
+func (b CompoundBuilder) RemoveLimit() CompoundBuilder {
+	return builder.Delete(b, "Limit").(CompoundBuilder)
+}
+
+// Offset sets a OFFSET clause on the query.
+func (b CompoundBuilder) Offset(offset uint64) CompoundBuilder {
+	return builder.Set(b, "Offset", fmt.Sprintf("%d", offset)).(CompoundBuilder)
+}
+
+// RemoveOffset removes OFFSET clause.
+func (b CompoundBuilder) RemoveOffset() CompoundBuilder {
+	return builder.Delete(b, "Offset").(CompoundBuilder)
 }
@@ -1,6 +1,8 @@
 package sq
 
 import (
+	"context"
+	"database/sql/driver"
 	"fmt"
 	"testing"
 
@@ -10,7 +12,7 @@ import (
 func TestSetOp(t *testing.T) {
 	testCases := []struct {
 		Sep string
-		Fn  func(...Sqlizer) Sqlizer
+		Fn  func(...Sqlizer) CompoundBuilder
 	}{
 		{"UNION ALL", UnionAll},
 		{"UNION DISTINCT", UnionDistinct},
@@ -57,3 +59,94 @@ func TestSetOp(t *testing.T) {
 		})
 	}
 }
+
+func TestCompoundBuilder_Mixed(t *testing.T) {
+	b := UnionAll(
+		Select("id").From("users").Where("org_id = ?", 1),
+		Select("id").From("admins"),
+	).
+		Except(Select("id").From("banned").Where("until > ?", 2)).
+		Intersect(IntersectAll(Select("id").From("a"), Select("id").From("b")))
+
+	sql, args, err := b.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "((SELECT id FROM users WHERE org_id = ? UNION ALL SELECT id FROM admins) "+
+		"EXCEPT SELECT id FROM banned WHERE until > ?) "+
+		"INTERSECT (SELECT id FROM a INTERSECT ALL SELECT id FROM b)", sql)
+	require.Equal(t, []any{1, 2}, args)
+
+	_, _, err = b.Dialect(SQLite).ToSql()
+	require.EqualError(t, err, "parenthesized compound query parts is not supported by SQLite")
+}
+
+func TestCompoundBuilder_OrderedParts(t *testing.T) {
+	b := UnionDistinct(
+		Select("id").From("a").OrderBy("id").Limit(1),
+		Select("id").From("b"),
+		Select("id").From("c").Offset(2),
+	)
+
+	sql, _, err := b.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "(SELECT id FROM a ORDER BY id LIMIT 1) UNION DISTINCT SELECT id FROM b UNION DISTINCT (SELECT id FROM c OFFSET 2)", sql)
+
+	_, _, err = b.Dialect(SQLite).ToSql()
+	require.EqualError(t, err, "parenthesized compound query parts is not supported by SQLite")
+
+	sql, _, err = UnionAll(Select("id").From("a"), Select("id").From("b")).Dialect(SQLite).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT id FROM a UNION ALL SELECT id FROM b", sql)
+}
+
+func TestCompoundBuilder_OrderByLimit(t *testing.T) {
+	b := Select("id").From("users").Where("org_id = ?", 1).
+		Union(Select("id").From("admins").Where("org_id = ?", 2)).
+		OrderBy("id DESC").
+		Limit(10).
+		Offset(20)
+
+	sql, args, err := b.PlaceholderFormat(Dollar).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT id FROM users WHERE org_id = $1 UNION SELECT id FROM admins WHERE org_id = $2 ORDER BY id DESC LIMIT 10 OFFSET 20", sql)
+	require.Equal(t, []any{1, 2}, args)
+
+	sql, _, err = b.ToSqlRaw()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT id FROM users WHERE org_id = ? UNION SELECT id FROM admins WHERE org_id = ? ORDER BY id DESC LIMIT 10 OFFSET 20", sql)
+
+	sql, _, err = b.RemoveOffset().Dialect(SQLServer).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT id FROM users WHERE org_id = @p1 UNION SELECT id FROM admins WHERE org_id = @p2 ORDER BY id DESC OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY", sql)
+
+	_, _, err = b.RemoveOrderBy().Dialect(Oracle).ToSql()
+	require.EqualError(t, err, "OFFSET requires ORDER BY on Oracle")
+
+	_, _, err = b.RemoveOrderBy().RemoveOffset().Dialect(SQLServer).ToSql()
+	require.EqualError(t, err, "LIMIT requires ORDER BY on SQL Server")
+}
+
+func TestCompoundBuilder_InheritsConfig(t *testing.T) {
+	db, fake := newFakeDB([]string{"id"}, []driver.Value{int64(1)})
+
+	rows, err := StatementBuilder.PlaceholderFormat(Dollar).RunWith(db).
+		Select("id").From("users").Where("org_id = ?", 1).
+		UnionAll(Select("id").From("admins").Where("org_id = ?", 2)).
+		QueryContext(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+	require.Equal(t, []string{
+		"SELECT id FROM users WHERE org_id = $1 UNION ALL SELECT id FROM admins WHERE org_id = $2",
+	}, fake.queries)
+}
+
+func TestCompoundBuilder_Subquery(t *testing.T) {
+	sql, args, err := Select("count(*)").
+		FromSelect(Select("id").From("users").Where("org_id = ?", 1).
+			Union(Select("id").From("admins").Where("org_id = ?", 2)), "u").
+		Where("u.id > ?", 3).
+		PlaceholderFormat(Dollar).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT count(*) FROM (SELECT id FROM users WHERE org_id = $1 UNION SELECT id FROM admins WHERE org_id = $2) AS u WHERE u.id > $3", sql)
+	require.Equal(t, []any{1, 2, 3}, args)
+}
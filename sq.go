@@ -4,8 +4,13 @@
 package sq
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
+
+	"github.com/userhubdev/sq/internal/builder"
 )
 
 // Sqlizer is the interface that wraps the ToSql method.
@@ -35,7 +40,70 @@ type RawSqlizer interface {
 	ToSqlRaw() (string, []any, error)
 }
 
-// Debug calls ToSql on s and shows the approximate SQL to be executed
+// Runner is the interface that wraps the context-aware database/sql methods
+// used to execute queries.
+//
+// It is satisfied by *sql.DB, *sql.Tx and *sql.Conn.
+type Runner interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// RowScanner is the interface that wraps the Scan method.
+//
+// Scan behaves like database/sql.Row.Scan.
+type RowScanner interface {
+	Scan(dest ...any) error
+}
+
+// Row wraps a RowScanner, deferring any error from building the query until
+// Scan is called.
+type Row struct {
+	RowScanner
+	err error
+}
+
+// Scan returns Row.err or calls RowScanner.Scan.
+func (r *Row) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.RowScanner.Scan(dest...)
+}
+
+// ErrRunnerNotSet is returned by methods that need a Runner if it isn't set.
+var ErrRunnerNotSet = errors.New("cannot run; no Runner set (RunWith)")
+
+// ExecContextWith ExecContexts the SQL returned by s with db.
+func ExecContextWith(ctx context.Context, db Runner, s Sqlizer) (res sql.Result, err error) {
+	query, args, err := s.ToSql()
+	if err != nil {
+		return
+	}
+	return db.ExecContext(ctx, query, args...)
+}
+
+// QueryContextWith QueryContexts the SQL returned by s with db.
+func QueryContextWith(ctx context.Context, db Runner, s Sqlizer) (rows *sql.Rows, err error) {
+	query, args, err := s.ToSql()
+	if err != nil {
+		return
+	}
+	return db.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContextWith QueryRowContexts the SQL returned by s with db.
+func QueryRowContextWith(ctx context.Context, db Runner, s Sqlizer) RowScanner {
+	query, args, err := s.ToSql()
+	if err != nil {
+		return &Row{err: err}
+	}
+	return &Row{RowScanner: db.QueryRowContext(ctx, query, args...)}
+}
+
+// Debug calls ToSql (or ToSqlRaw if s is a RawSqlizer) on s and shows the
+// approximate SQL to be executed
 //
 // If ToSql returns an error, the result of this method will look like:
 // "[ToSql error: %s]" or "[Debug error: %s]"
@@ -45,52 +113,59 @@ type RawSqlizer interface {
 // not try very hard to ensure it. Additionally, executing the output of this
 // function with any untrusted user input is certainly insecure.
 func Debug(s Sqlizer) string {
-	sql, args, err := s.ToSql()
+	var sql string
+	var args []any
+	var err error
+	if raw, ok := s.(RawSqlizer); ok {
+		// Placeholders are only finalized after they have been replaced.
+		sql, args, err = raw.ToSqlRaw()
+	} else {
+		sql, args, err = s.ToSql()
+	}
 	if err != nil {
 		return fmt.Sprintf("[ToSql error: %s]", err)
 	}
 
-	var placeholder string
-	downCast, ok := s.(placeholderDebugger)
-	if !ok {
-		placeholder = "?"
-	} else {
-		placeholder = downCast.debugPlaceholder()
-	}
-	// TODO: dedupe this with placeholder.go
 	b := &strings.Builder{}
 	i := 0
-	for {
-		p := strings.Index(sql, placeholder)
-		if p == -1 {
-			break
-		}
-		if len(sql[p:]) > 1 && sql[p:p+2] == "??" { // escape ?? => ?
-			b.WriteString(sql[:p])
-			b.WriteString("?")
-			if len(sql[p:]) == 1 {
-				break
+	lexSql(sql, debugRenderContext(s).backslashEscapes(), func(tok sqlToken, text string) {
+		switch tok {
+		case sqlText:
+			b.WriteString(text)
+		case sqlPlaceholder:
+			if i < len(args) {
+				fmt.Fprintf(b, "'%v'", args[i])
 			}
-			sql = sql[p+2:]
-		} else {
-			if i+1 > len(args) {
-				return fmt.Sprintf(
-					"[Debug error: too many placeholders in %#v for %d args]",
-					sql, len(args))
-			}
-			b.WriteString(sql[:p])
-			fmt.Fprintf(b, "'%v'", args[i])
-			// advance our sql string "cursor" beyond the arg we placed
-			sql = sql[p+1:]
 			i++
+		case sqlEscape:
+			b.WriteString("?")
 		}
+	})
+
+	if i > len(args) {
+		return fmt.Sprintf(
+			"[Debug error: too many placeholders in %#v for %d args]",
+			sql, len(args))
 	}
 	if i < len(args) {
 		return fmt.Sprintf(
 			"[Debug error: not enough placeholders in %#v for %d args]",
 			sql, len(args))
 	}
-	// "append" any remaning sql that won't need interpolating
-	b.WriteString(sql)
 	return b.String()
 }
+
+// debugRenderContext returns the Dialect and PlaceholderFormat of s if it is a
+// builder, so that Debug lexes its string literals as the builder did.
+func debugRenderContext(s Sqlizer) (rc renderContext) {
+	switch s.(type) {
+	case SelectBuilder, InsertBuilder, UpdateBuilder, DeleteBuilder, MergeBuilder, CompoundBuilder, WithBuilder:
+		if d, ok := builder.Get(s, "Dialect"); ok {
+			rc.dialect, _ = d.(Dialect)
+		}
+		if f, ok := builder.Get(s, "PlaceholderFormat"); ok {
+			rc.format, _ = f.(PlaceholderFormat)
+		}
+	}
+	return
+}
@@ -1,7 +1,13 @@
 package sq
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -11,23 +17,19 @@ var testDebugUpdateSQL = Update("table").SetMap(Eq{"x": 1, "y": "val"})
 var expectedDebugUpdateSQL = "UPDATE table SET x = '1', y = 'val'"
 
 func TestDebugUpdateColon(t *testing.T) {
-	testDebugUpdateSQL.PlaceholderFormat(Colon)
-	require.Equal(t, expectedDebugUpdateSQL, Debug(testDebugUpdateSQL))
+	require.Equal(t, expectedDebugUpdateSQL, Debug(testDebugUpdateSQL.PlaceholderFormat(Colon)))
 }
 
 func TestDebugUpdateAtp(t *testing.T) {
-	testDebugUpdateSQL.PlaceholderFormat(AtP)
-	require.Equal(t, expectedDebugUpdateSQL, Debug(testDebugUpdateSQL))
+	require.Equal(t, expectedDebugUpdateSQL, Debug(testDebugUpdateSQL.PlaceholderFormat(AtP)))
 }
 
 func TestDebugUpdateDollar(t *testing.T) {
-	testDebugUpdateSQL.PlaceholderFormat(Dollar)
-	require.Equal(t, expectedDebugUpdateSQL, Debug(testDebugUpdateSQL))
+	require.Equal(t, expectedDebugUpdateSQL, Debug(testDebugUpdateSQL.PlaceholderFormat(Dollar)))
 }
 
 func TestDebugUpdateQuestion(t *testing.T) {
-	testDebugUpdateSQL.PlaceholderFormat(Question)
-	require.Equal(t, expectedDebugUpdateSQL, Debug(testDebugUpdateSQL))
+	require.Equal(t, expectedDebugUpdateSQL, Debug(testDebugUpdateSQL.PlaceholderFormat(Question)))
 }
 
 var testDebugDeleteSQL = Delete("table").Where(And{
@@ -37,46 +39,38 @@ var testDebugDeleteSQL = Delete("table").Where(And{
 var expectedDebugDeleteSQL = "DELETE FROM table WHERE (column = 'val' AND other = '1')"
 
 func TestDebugDeleteColon(t *testing.T) {
-	testDebugDeleteSQL.PlaceholderFormat(Colon)
-	require.Equal(t, expectedDebugDeleteSQL, Debug(testDebugDeleteSQL))
+	require.Equal(t, expectedDebugDeleteSQL, Debug(testDebugDeleteSQL.PlaceholderFormat(Colon)))
 }
 
 func TestDebugDeleteAtp(t *testing.T) {
-	testDebugDeleteSQL.PlaceholderFormat(AtP)
-	require.Equal(t, expectedDebugDeleteSQL, Debug(testDebugDeleteSQL))
+	require.Equal(t, expectedDebugDeleteSQL, Debug(testDebugDeleteSQL.PlaceholderFormat(AtP)))
 }
 
 func TestDebugDeleteDollar(t *testing.T) {
-	testDebugDeleteSQL.PlaceholderFormat(Dollar)
-	require.Equal(t, expectedDebugDeleteSQL, Debug(testDebugDeleteSQL))
+	require.Equal(t, expectedDebugDeleteSQL, Debug(testDebugDeleteSQL.PlaceholderFormat(Dollar)))
 }
 
 func TestDebugDeleteQuestion(t *testing.T) {
-	testDebugDeleteSQL.PlaceholderFormat(Question)
-	require.Equal(t, expectedDebugDeleteSQL, Debug(testDebugDeleteSQL))
+	require.Equal(t, expectedDebugDeleteSQL, Debug(testDebugDeleteSQL.PlaceholderFormat(Question)))
 }
 
 var testDebugInsertSQL = Insert("table").Values(1, "test")
 var expectedDebugInsertSQL = "INSERT INTO table VALUES ('1','test')"
 
 func TestDebugInsertColon(t *testing.T) {
-	testDebugInsertSQL.PlaceholderFormat(Colon)
-	require.Equal(t, expectedDebugInsertSQL, Debug(testDebugInsertSQL))
+	require.Equal(t, expectedDebugInsertSQL, Debug(testDebugInsertSQL.PlaceholderFormat(Colon)))
 }
 
 func TestDebugInsertAtp(t *testing.T) {
-	testDebugInsertSQL.PlaceholderFormat(AtP)
-	require.Equal(t, expectedDebugInsertSQL, Debug(testDebugInsertSQL))
+	require.Equal(t, expectedDebugInsertSQL, Debug(testDebugInsertSQL.PlaceholderFormat(AtP)))
 }
 
 func TestDebugInsertDollar(t *testing.T) {
-	testDebugInsertSQL.PlaceholderFormat(Dollar)
-	require.Equal(t, expectedDebugInsertSQL, Debug(testDebugInsertSQL))
+	require.Equal(t, expectedDebugInsertSQL, Debug(testDebugInsertSQL.PlaceholderFormat(Dollar)))
 }
 
 func TestDebugInsertQuestion(t *testing.T) {
-	testDebugInsertSQL.PlaceholderFormat(Question)
-	require.Equal(t, expectedDebugInsertSQL, Debug(testDebugInsertSQL))
+	require.Equal(t, expectedDebugInsertSQL, Debug(testDebugInsertSQL.PlaceholderFormat(Question)))
 }
 
 var testDebugSelectSQL = Select("*").From("table").Where(And{
@@ -86,23 +80,19 @@ var testDebugSelectSQL = Select("*").From("table").Where(And{
 var expectedDebugSelectSQL = "SELECT * FROM table WHERE (column = 'val' AND other = '1')"
 
 func TestDebugSelectColon(t *testing.T) {
-	testDebugSelectSQL.PlaceholderFormat(Colon)
-	require.Equal(t, expectedDebugSelectSQL, Debug(testDebugSelectSQL))
+	require.Equal(t, expectedDebugSelectSQL, Debug(testDebugSelectSQL.PlaceholderFormat(Colon)))
 }
 
 func TestDebugSelectAtp(t *testing.T) {
-	testDebugSelectSQL.PlaceholderFormat(AtP)
-	require.Equal(t, expectedDebugSelectSQL, Debug(testDebugSelectSQL))
+	require.Equal(t, expectedDebugSelectSQL, Debug(testDebugSelectSQL.PlaceholderFormat(AtP)))
 }
 
 func TestDebugSelectDollar(t *testing.T) {
-	testDebugSelectSQL.PlaceholderFormat(Dollar)
-	require.Equal(t, expectedDebugSelectSQL, Debug(testDebugSelectSQL))
+	require.Equal(t, expectedDebugSelectSQL, Debug(testDebugSelectSQL.PlaceholderFormat(Dollar)))
 }
 
 func TestDebugSelectQuestion(t *testing.T) {
-	testDebugSelectSQL.PlaceholderFormat(Question)
-	require.Equal(t, expectedDebugSelectSQL, Debug(testDebugSelectSQL))
+	require.Equal(t, expectedDebugSelectSQL, Debug(testDebugSelectSQL.PlaceholderFormat(Question)))
 }
 
 func TestDebug(t *testing.T) {
@@ -111,6 +101,12 @@ func TestDebug(t *testing.T) {
 	require.Equal(t, expectedDebug, Debug(sqlizer))
 }
 
+func TestDebugMySQL(t *testing.T) {
+	// MySQL escapes quotes with backslashes, so the first ? is in a literal
+	b := Select("*").From("t").Where("a = 'x\\'?' AND b = ?", 1).Dialect(MySQL)
+	require.Equal(t, "SELECT * FROM t WHERE a = 'x\\'?' AND b = '1'", Debug(b))
+}
+
 func TestDebugErrors(t *testing.T) {
 	errorMsg := Debug(Expr("x = ?", 1, 2)) // Not enough placeholders
 	require.True(t, strings.HasPrefix(errorMsg, "[Debug error: "))
@@ -121,3 +117,153 @@ func TestDebugErrors(t *testing.T) {
 	errorMsg = Debug(Lt{"x": nil}) // Cannot use nil values with Lt
 	require.True(t, strings.HasPrefix(errorMsg, "[ToSql error: "))
 }
+
+func TestRunWith(t *testing.T) {
+	db, fake := newFakeDB([]string{"a"}, []driver.Value{int64(1)})
+	ctx := context.Background()
+
+	_, err := Update("t").Set("a", 1).RunWith(db).ExecContext(ctx)
+	require.NoError(t, err)
+
+	_, err = Insert("t").Values(2).RunWith(db).PlaceholderFormat(Dollar).ExecContext(ctx)
+	require.NoError(t, err)
+
+	_, err = Delete("t").Where("a = ?", 3).RunWith(db).ExecContext(ctx)
+	require.NoError(t, err)
+
+	var a int
+	err = Select("a").From("t").RunWith(db).QueryRowContext(ctx).Scan(&a)
+	require.NoError(t, err)
+	require.Equal(t, 1, a)
+
+	rows, err := Select("a").From("t").RunWith(db).QueryContext(ctx)
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+
+	require.Equal(t, []string{
+		"UPDATE t SET a = ?",
+		"INSERT INTO t VALUES ($1)",
+		"DELETE FROM t WHERE a = ?",
+		"SELECT a FROM t",
+		"SELECT a FROM t",
+	}, fake.queries)
+	require.Equal(t, [][]any{{int64(1)}, {int64(2)}, {int64(3)}, nil, nil}, fake.args)
+}
+
+func TestRunWithStatementBuilder(t *testing.T) {
+	db, fake := newFakeDB(nil)
+
+	sb := StatementBuilder.PlaceholderFormat(Dollar).RunWith(db)
+	_, err := sb.Delete("t").Where("a = ?", 1).ExecContext(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"DELETE FROM t WHERE a = $1"}, fake.queries)
+}
+
+func TestRunnerNotSet(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := Select("a").ExecContext(ctx)
+	require.Equal(t, ErrRunnerNotSet, err)
+
+	_, err = Insert("t").Values(1).QueryContext(ctx)
+	require.Equal(t, ErrRunnerNotSet, err)
+
+	err = Update("t").Set("a", 1).QueryRowContext(ctx).Scan()
+	require.Equal(t, ErrRunnerNotSet, err)
+
+	_, err = Delete("t").ExecContext(ctx)
+	require.Equal(t, ErrRunnerNotSet, err)
+}
+
+func TestQueryRowContextWithToSqlError(t *testing.T) {
+	db, fake := newFakeDB(nil)
+
+	err := QueryRowContextWith(context.Background(), db, Select()).Scan()
+	require.EqualError(t, err, "select statements must have at least one result column")
+	require.Empty(t, fake.queries)
+}
+
+// fakeDB is a minimal database/sql driver which records the statements it is
+// asked to run and returns a fixed result set for every query.
+type fakeDB struct {
+	mu      sync.Mutex
+	queries []string
+	args    [][]any
+	columns []string
+	rows    [][]driver.Value
+}
+
+func newFakeDB(columns []string, rows ...[]driver.Value) (*sql.DB, *fakeDB) {
+	fake := &fakeDB{columns: columns, rows: rows}
+	return sql.OpenDB(fake), fake
+}
+
+func (f *fakeDB) record(query string, args []driver.NamedValue) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var values []any
+	for _, arg := range args {
+		values = append(values, arg.Value)
+	}
+	f.queries = append(f.queries, query)
+	f.args = append(f.args, values)
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{f}, nil }
+
+func (f *fakeDB) Driver() driver.Driver { return fakeDriver{f} }
+
+type fakeDriver struct{ db *fakeDB }
+
+func (d fakeDriver) Open(string) (driver.Conn, error) { return fakeConn(d), nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+
+func (c fakeConn) Close() error { return nil }
+
+func (c fakeConn) Begin() (driver.Tx, error) { return fakeTx{c.db}, nil }
+
+func (c fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.record(query, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (c fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.record(query, args)
+	return &fakeRows{columns: c.db.columns, rows: c.db.rows}, nil
+}
+
+type fakeTx struct{ db *fakeDB }
+
+func (tx fakeTx) Commit() error {
+	tx.db.record("COMMIT", nil)
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.db.record("ROLLBACK", nil)
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
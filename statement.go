@@ -31,6 +31,11 @@ func (b StatementBuilderType) Delete(from string) DeleteBuilder {
 	return DeleteBuilder(b).From(from)
 }
 
+// Merge returns a MergeBuilder for this StatementBuilderType.
+func (b StatementBuilderType) Merge(into string) MergeBuilder {
+	return MergeBuilder(b).Into(into)
+}
+
 // With returns a WithBuilder for this StatementBuilderType.
 func (b StatementBuilderType) With() WithBuilder {
 	return WithBuilder(b)
@@ -41,6 +46,22 @@ func (b StatementBuilderType) PlaceholderFormat(f PlaceholderFormat) StatementBu
 	return builder.Set(b, "PlaceholderFormat", f).(StatementBuilderType)
 }
 
+// Dialect sets the Dialect field for any child builders, and sets the
+// PlaceholderFormat to the dialect's default. A nil Dialect clears it, keeping
+// the PlaceholderFormat.
+func (b StatementBuilderType) Dialect(d Dialect) StatementBuilderType {
+	if d == nil {
+		return builder.Delete(b, "Dialect").(StatementBuilderType)
+	}
+	b = builder.Set(b, "Dialect", d).(StatementBuilderType)
+	return b.PlaceholderFormat(d.PlaceholderFormat())
+}
+
+// RunWith sets the RunWith field for any child builders.
+func (b StatementBuilderType) RunWith(runner Runner) StatementBuilderType {
+	return builder.Set(b, "RunWith", runner).(StatementBuilderType)
+}
+
 // Where adds WHERE expressions to the query.
 //
 // See SelectBuilder.Where for more information.
@@ -87,6 +108,13 @@ func Delete(from string) DeleteBuilder {
 	return StatementBuilder.Delete(from)
 }
 
+// Merge returns a new MergeBuilder with the given target table name.
+//
+// See MergeBuilder.Into.
+func Merge(into string) MergeBuilder {
+	return StatementBuilder.Merge(into)
+}
+
 // With returns a new WithBuilder.
 func With() WithBuilder {
 	return StatementBuilder.With()
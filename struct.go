@@ -0,0 +1,238 @@
+package sq
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// structField describes a struct field mapped to a column with a db tag.
+type structField struct {
+	column string
+	index  []int
+
+	// omitEmpty skips the field when setting columns from a zero value.
+	omitEmpty bool
+	// readOnly skips the field when setting columns.
+	readOnly bool
+	// pk marks the field as (part of) the primary key, which is never updated.
+	pk bool
+}
+
+// structInfo holds the column mapping of a struct type.
+type structInfo struct {
+	fields   []*structField
+	byColumn map[string]*structField
+}
+
+var structInfoCache sync.Map // map[reflect.Type]*structInfo
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// getStructInfo returns the (cached) column mapping of the given struct type.
+//
+// Fields are mapped using their db tag, e.g. `db:"name"`. Fields without a db
+// tag or tagged with `db:"-"` are ignored, except for embedded structs without
+// a tag, whose fields are mapped as if they were declared on the outer struct.
+//
+// The tag name may be followed by comma-separated options:
+//
+//	omitempty - don't set the column if the field has its zero value
+//	readonly  - never set the column (e.g. for generated columns)
+//	pk        - the column is a primary key and is never updated
+func getStructInfo(t reflect.Type) (*structInfo, error) {
+	if info, ok := structInfoCache.Load(t); ok {
+		return info.(*structInfo), nil
+	}
+
+	var fields []*structField
+	if err := collectFields(t, nil, &fields); err != nil {
+		return nil, err
+	}
+
+	// Like Go's own field selection, the shallowest field of a column wins,
+	// and only fields at that depth are ambiguous.
+	depth := make(map[string]int)
+	for _, f := range fields {
+		if d, ok := depth[f.column]; !ok || len(f.index) < d {
+			depth[f.column] = len(f.index)
+		}
+	}
+
+	info := &structInfo{byColumn: make(map[string]*structField)}
+	for _, f := range fields {
+		if len(f.index) != depth[f.column] {
+			continue
+		}
+		if _, ok := info.byColumn[f.column]; ok {
+			return nil, fmt.Errorf("duplicate column %q in %s", f.column, t)
+		}
+		info.fields = append(info.fields, f)
+		info.byColumn[f.column] = f
+	}
+
+	actual, _ := structInfoCache.LoadOrStore(t, info)
+	return actual.(*structInfo), nil
+}
+
+// collectFields appends the db tagged fields of t, including those of its
+// embedded structs, to fields in declaration order.
+func collectFields(t reflect.Type, index []int, fields *[]*structField) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, hasTag := f.Tag.Lookup("db")
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		if !hasTag {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				if !f.IsExported() {
+					// Cannot be allocated through reflection.
+					continue
+				}
+				ft = ft.Elem()
+			}
+			if f.Anonymous && !isScanLeaf(ft) {
+				if err := collectFields(ft, fieldIndex, fields); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" || name == "" {
+			continue
+		}
+
+		field := &structField{column: name, index: fieldIndex}
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "":
+			case "omitempty":
+				field.omitEmpty = true
+			case "readonly":
+				field.readOnly = true
+			case "pk":
+				field.pk = true
+			default:
+				return fmt.Errorf("unknown db tag option %q on %s.%s", opt, t, f.Name)
+			}
+		}
+		*fields = append(*fields, field)
+	}
+	return nil
+}
+
+// mustStructValue returns the struct value v points to, panicking with a useful
+// message if v is not a struct or a pointer to a struct.
+func mustStructValue(v any) (reflect.Value, *structInfo) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("expected struct or pointer to struct, not %T", v))
+	}
+
+	info, err := getStructInfo(rv.Type())
+	if err != nil {
+		panic(err)
+	}
+
+	return rv, info
+}
+
+// setColumns returns the columns and values of a struct for an INSERT or
+// UPDATE, skipping read-only fields, primary keys if skipPK is set and empty
+// omitempty fields if omitEmpty is set.
+func (info *structInfo) setColumns(rv reflect.Value, skipPK, omitEmpty bool) ([]string, []any) {
+	columns := make([]string, 0, len(info.fields))
+	values := make([]any, 0, len(info.fields))
+	for _, f := range info.fields {
+		if f.readOnly || (skipPK && f.pk) {
+			continue
+		}
+
+		fv, ok := fieldValue(rv, f.index)
+		if omitEmpty && f.omitEmpty && (!ok || fv.IsZero()) {
+			continue
+		}
+
+		columns = append(columns, f.column)
+		if ok {
+			values = append(values, fv.Interface())
+		} else {
+			values = append(values, nil)
+		}
+	}
+	return columns, values
+}
+
+// ColumnsFromStruct returns the column names of the db tagged fields of the
+// given struct (or pointer to struct) in declaration order, e.g.:
+//
+//	sq.Select(sq.ColumnsFromStruct(User{})...).From("users")
+//
+// See Get for how fields are mapped to columns. ColumnsFromStruct panics if v
+// is not a struct or a pointer to a struct.
+func ColumnsFromStruct(v any) []string {
+	_, info := mustStructValue(v)
+
+	columns := make([]string, len(info.fields))
+	for i, f := range info.fields {
+		columns[i] = f.column
+	}
+	return columns
+}
+
+// isScanLeaf reports whether values of type t are scanned as a single column
+// rather than mapped field by field.
+func isScanLeaf(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t == timeType {
+		return true
+	}
+	return reflect.PointerTo(t).Implements(scannerType) || t.Implements(valuerType)
+}
+
+// fieldValue is like reflect.Value.FieldByIndex, but returns false instead of
+// panicking if it steps through a nil embedded struct pointer.
+func fieldValue(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, but allocates nil embedded
+// struct pointers along the way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
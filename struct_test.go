@@ -0,0 +1,78 @@
+package sq
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type structTestUser struct {
+	ID        int64     `db:"id,pk"`
+	Email     string    `db:"email"`
+	Nickname  string    `db:"nickname,omitempty"`
+	CreatedAt time.Time `db:"created_at,readonly"`
+}
+
+func TestColumnsFromStruct(t *testing.T) {
+	require.Equal(t, []string{"id", "email", "nickname", "created_at"}, ColumnsFromStruct(structTestUser{}))
+	require.Equal(t, []string{"id", "email", "nickname", "created_at"}, ColumnsFromStruct(&structTestUser{}))
+
+	sql, _, err := Select(ColumnsFromStruct(scanUser{})...).From("users").ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT id, email, name FROM users", sql)
+
+	require.Panics(t, func() { ColumnsFromStruct(1) })
+}
+
+func TestStructInfoOptions(t *testing.T) {
+	info, err := getStructInfo(reflect.TypeOf(structTestUser{}))
+	require.NoError(t, err)
+	require.True(t, info.byColumn["id"].pk)
+	require.True(t, info.byColumn["nickname"].omitEmpty)
+	require.True(t, info.byColumn["created_at"].readOnly)
+
+	type badOption struct {
+		A int `db:"a,bogus"`
+	}
+	_, err = getStructInfo(reflect.TypeOf(badOption{}))
+	require.EqualError(t, err, `unknown db tag option "bogus" on sq.badOption.A`)
+}
+
+func TestStructInfoCached(t *testing.T) {
+	a, err := getStructInfo(reflect.TypeOf(structTestUser{}))
+	require.NoError(t, err)
+	b, err := getStructInfo(reflect.TypeOf(structTestUser{}))
+	require.NoError(t, err)
+	require.Same(t, a, b)
+}
+
+type structTestA struct {
+	X int `db:"x"`
+	A int `db:"a"`
+}
+
+type structTestB struct {
+	X int `db:"x"`
+	B int `db:"b"`
+}
+
+func TestStructInfoShadowing(t *testing.T) {
+	type shadowed struct {
+		structTestA
+		structTestB
+		X int `db:"x"`
+	}
+	info, err := getStructInfo(reflect.TypeOf(shadowed{}))
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "x"}, ColumnsFromStruct(shadowed{}))
+	require.Equal(t, []int{2}, info.byColumn["x"].index)
+
+	type ambiguous struct {
+		structTestA
+		structTestB
+	}
+	_, err = getStructInfo(reflect.TypeOf(ambiguous{}))
+	require.EqualError(t, err, `duplicate column "x" in sq.ambiguous`)
+}
@@ -0,0 +1,185 @@
+package sq
+
+// Table is a reference to a table and its columns, for use with Column
+// instead of hand-written column names. Tables are usually declared by code
+// generated with cmd/sqgen.
+//
+// Ex:
+//
+//	Select(Users.All()...).From(Users.String()).Where(Users.Email.Eq("a@b.c"))
+//	// SELECT users.id, users.email FROM users WHERE users.email = ?
+type Table struct {
+	name    string
+	alias   string
+	columns []string
+}
+
+// NewTable returns a reference to the table name with the given columns.
+func NewTable(name string, columns ...string) Table {
+	return Table{name: name, columns: columns}
+}
+
+// As returns the table with an alias, which qualifies the columns created
+// from it. An empty alias removes the alias.
+func (t Table) As(alias string) Table {
+	t.alias = alias
+	return t
+}
+
+// Name returns the name of the table.
+func (t Table) Name() string {
+	return t.name
+}
+
+// Ref returns the name which qualifies the columns of the table: its alias if
+// it has one, or else its name.
+func (t Table) Ref() string {
+	if len(t.alias) > 0 {
+		return t.alias
+	}
+	return t.name
+}
+
+// Columns returns the unqualified column names of the table, e.g. for
+// InsertBuilder.Columns.
+func (t Table) Columns() []string {
+	return append([]string(nil), t.columns...)
+}
+
+// All returns the qualified column names of the table, e.g. for
+// SelectBuilder.Columns.
+func (t Table) All() []string {
+	all := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		all[i] = t.Ref() + "." + c
+	}
+	return all
+}
+
+// String returns the table as used in a FROM or JOIN clause, e.g. "users" or
+// "users u".
+func (t Table) String() string {
+	if len(t.alias) > 0 {
+		return t.name + " " + t.alias
+	}
+	return t.name
+}
+
+// ToSql implements Sqlizer.
+func (t Table) ToSql() (string, []any, error) {
+	return t.String(), nil, nil
+}
+
+// Column is a reference to a column of a Table, whose values have type T.
+// Its predicates only accept values of type T, so a change of the column's
+// type becomes a compile error.
+type Column[T any] struct {
+	table string
+	name  string
+}
+
+// NewColumn returns a reference to the column name of table t.
+func NewColumn[T any](t Table, name string) Column[T] {
+	return Column[T]{table: t.Ref(), name: name}
+}
+
+// Name returns the unqualified name of the column, e.g. for
+// UpdateBuilder.Set.
+func (c Column[T]) Name() string {
+	return c.name
+}
+
+// String returns the qualified name of the column, e.g. "users.email".
+func (c Column[T]) String() string {
+	if len(c.table) > 0 {
+		return c.table + "." + c.name
+	}
+	return c.name
+}
+
+// ToSql implements Sqlizer.
+func (c Column[T]) ToSql() (string, []any, error) {
+	return c.String(), nil, nil
+}
+
+// As returns the column with an alias, for use as a result column.
+func (c Column[T]) As(alias string) string {
+	return c.String() + " AS " + alias
+}
+
+// Asc returns an ascending ORDER BY expression for the column.
+func (c Column[T]) Asc() string {
+	return c.String() + " ASC"
+}
+
+// Desc returns a descending ORDER BY expression for the column.
+func (c Column[T]) Desc() string {
+	return c.String() + " DESC"
+}
+
+// Eq returns a "column = value" predicate, or "column IS NULL" if v is nil.
+// See EqCol.
+func (c Column[T]) Eq(v T) Sqlizer {
+	return EqCol(c.String(), v)
+}
+
+// NotEq returns a "column <> value" predicate. See NotEqCol.
+func (c Column[T]) NotEq(v T) Sqlizer {
+	return NotEqCol(c.String(), v)
+}
+
+// Lt returns a "column < value" predicate.
+func (c Column[T]) Lt(v T) Sqlizer {
+	return LtCol(c.String(), v)
+}
+
+// LtOrEq returns a "column <= value" predicate.
+func (c Column[T]) LtOrEq(v T) Sqlizer {
+	return LtOrEqCol(c.String(), v)
+}
+
+// Gt returns a "column > value" predicate.
+func (c Column[T]) Gt(v T) Sqlizer {
+	return GtCol(c.String(), v)
+}
+
+// GtOrEq returns a "column >= value" predicate.
+func (c Column[T]) GtOrEq(v T) Sqlizer {
+	return GtOrEqCol(c.String(), v)
+}
+
+// In returns a "column IN (values...)" predicate. See In.
+func (c Column[T]) In(vs []T) Sqlizer {
+	return In(c.String(), vs)
+}
+
+// NotIn returns a "column NOT IN (values...)" predicate. See NotIn.
+func (c Column[T]) NotIn(vs []T) Sqlizer {
+	return NotIn(c.String(), vs)
+}
+
+// Between returns a "column BETWEEN low AND high" predicate.
+func (c Column[T]) Between(low, high T) Sqlizer {
+	return Between(c.String(), low, high)
+}
+
+// IsDistinctFrom returns a "column IS DISTINCT FROM value" predicate. See
+// IsDistinctFrom.
+func (c Column[T]) IsDistinctFrom(v T) Sqlizer {
+	return IsDistinctFrom(c.String(), v)
+}
+
+// IsNull returns a "column IS NULL" predicate.
+func (c Column[T]) IsNull() Sqlizer {
+	return Expr(c.String() + " IS NULL")
+}
+
+// IsNotNull returns a "column IS NOT NULL" predicate.
+func (c Column[T]) IsNotNull() Sqlizer {
+	return Expr(c.String() + " IS NOT NULL")
+}
+
+// EqColumn returns a "column = other" predicate, e.g. for a JOIN condition.
+func (c Column[T]) EqColumn(other Column[T]) Sqlizer {
+	return Expr(c.String() + " = " + other.String())
+}
@@ -0,0 +1,87 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testUsersTable struct {
+	Table
+	ID    Column[int64]
+	Email Column[string]
+	Name  Column[*string]
+}
+
+func newTestUsersTable(alias string) testUsersTable {
+	t := NewTable("users", "id", "email", "name").As(alias)
+	return testUsersTable{
+		Table: t,
+		ID:    NewColumn[int64](t, "id"),
+		Email: NewColumn[string](t, "email"),
+		Name:  NewColumn[*string](t, "name"),
+	}
+}
+
+func TestTable(t *testing.T) {
+	users := newTestUsersTable("")
+
+	sql, args, err := Select(users.All()...).
+		From(users.String()).
+		Where(users.Email.Eq("a@example.com")).
+		Where(users.ID.In([]int64{1, 2})).
+		Where(users.Name.Eq(nil)).
+		OrderBy(users.ID.Desc()).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT users.id, users.email, users.name FROM users "+
+		"WHERE users.email = ? AND users.id IN (?,?) AND users.name IS NULL "+
+		"ORDER BY users.id DESC", sql)
+	require.Equal(t, []any{"a@example.com", int64(1), int64(2)}, args)
+
+	require.Equal(t, "users", users.Table.Name())
+	require.Equal(t, []string{"id", "email", "name"}, users.Columns())
+}
+
+func TestTable_Alias(t *testing.T) {
+	u := newTestUsersTable("u")
+	m := newTestUsersTable("m")
+
+	sql, args, err := Select(u.ID.String(), m.Email.As("manager_email")).
+		From(u.String()).
+		JoinOn(m, u.ID.EqColumn(m.ID)).
+		Where(u.ID.Gt(10)).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT u.id, m.email AS manager_email FROM users u "+
+		"JOIN users m ON u.id = m.id WHERE u.id > ?", sql)
+	require.Equal(t, []any{int64(10)}, args)
+	require.Equal(t, "users", u.Table.Name())
+	require.Equal(t, "u", u.Ref())
+}
+
+func TestColumn_Predicates(t *testing.T) {
+	users := newTestUsersTable("")
+
+	tests := []struct {
+		pred Sqlizer
+		sql  string
+	}{
+		{users.ID.NotEq(1), "users.id <> ?"},
+		{users.ID.Lt(1), "users.id < ?"},
+		{users.ID.LtOrEq(1), "users.id <= ?"},
+		{users.ID.GtOrEq(1), "users.id >= ?"},
+		{users.ID.NotIn([]int64{1}), "users.id NOT IN (?)"},
+		{users.ID.Between(1, 2), "users.id BETWEEN ? AND ?"},
+		{users.Name.IsDistinctFrom(nil), "users.name IS DISTINCT FROM NULL"},
+		{users.Email.IsNull(), "users.email IS NULL"},
+		{users.Email.IsNotNull(), "users.email IS NOT NULL"},
+		{users.ID, "users.id"},
+	}
+
+	for _, test := range tests {
+		sql, _, err := test.pred.ToSql()
+		require.NoError(t, err)
+		require.Equal(t, test.sql, sql)
+	}
+}
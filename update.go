@@ -1,7 +1,10 @@
 package sq
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"io"
 	"sort"
 	"strings"
 
@@ -10,15 +13,20 @@ import (
 
 type updateData struct {
 	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
+	RunWith           Runner
 	Prefixes          []Sqlizer
+	WithParts         []withPart
 	Table             string
 	SetClauses        []setClause
 	From              Sqlizer
+	Joins             []Sqlizer
 	WhereParts        []Sqlizer
 	OrderBys          []string
 	Limit             string
 	Offset            string
 	Suffixes          []Sqlizer
+	Returning         []returningPart
 }
 
 type setClause struct {
@@ -26,7 +34,85 @@ type setClause struct {
 	value  any
 }
 
+// appendSetClauses writes "column = value" for each clause, separated by
+// commas. Subqueries are parenthesized.
+func appendSetClauses(rc renderContext, clauses []setClause, w io.Writer, args []any) ([]any, error) {
+	setSqls := make([]string, len(clauses))
+	for i, setClause := range clauses {
+		var valSql string
+		if vs, ok := setClause.value.(Sqlizer); ok {
+			vsql, vargs, err := subqueryToSql(rc, vs)
+			if err != nil {
+				return nil, err
+			}
+			valSql = vsql
+			args = append(args, vargs...)
+		} else {
+			valSql = "?"
+			args = append(args, setClause.value)
+		}
+		setSqls[i] = fmt.Sprintf("%s = %s", setClause.column, valSql)
+	}
+	_, err := io.WriteString(w, strings.Join(setSqls, ", "))
+	return args, err
+}
+
+// tableAlias returns the alias of a table expression such as "users u" or
+// "users AS u", or the table itself if it has no alias.
+func tableAlias(table string) string {
+	fields := strings.Fields(table)
+	if len(fields) == 0 {
+		return table
+	}
+	return fields[len(fields)-1]
+}
+
+// sortedSetClauses returns a setClause for each key/value pair in clauses,
+// sorted by key.
+func sortedSetClauses(clauses map[string]any) []setClause {
+	set := make([]setClause, 0, len(clauses))
+	for _, key := range getSortedKeys(clauses) {
+		set = append(set, setClause{column: key, value: clauses[key]})
+	}
+	return set
+}
+
+func (d *updateData) ExecContext(ctx context.Context) (sql.Result, error) {
+	if d.RunWith == nil {
+		return nil, ErrRunnerNotSet
+	}
+	return ExecContextWith(ctx, d.RunWith, d)
+}
+
+func (d *updateData) QueryContext(ctx context.Context) (*sql.Rows, error) {
+	if d.RunWith == nil {
+		return nil, ErrRunnerNotSet
+	}
+	return QueryContextWith(ctx, d.RunWith, d)
+}
+
+func (d *updateData) QueryRowContext(ctx context.Context) RowScanner {
+	if d.RunWith == nil {
+		return &Row{err: ErrRunnerNotSet}
+	}
+	return QueryRowContextWith(ctx, d.RunWith, d)
+}
+
 func (d *updateData) ToSql() (sqlStr string, args []any, err error) {
+	sqlStr, args, err = d.ToSqlRaw()
+	if err != nil {
+		return
+	}
+
+	sqlStr, args, err = replacePlaceholders(d.PlaceholderFormat, sqlStr, args)
+	return
+}
+
+func (d *updateData) ToSqlRaw() (sqlStr string, args []any, err error) {
+	return d.toSqlRaw(renderContext{dialect: d.Dialect, format: d.PlaceholderFormat})
+}
+
+func (d *updateData) toSqlRaw(rc renderContext) (sqlStr string, args []any, err error) {
 	if len(d.Table) == 0 {
 		err = fmt.Errorf("update statements must specify a table")
 		return
@@ -39,7 +125,7 @@ func (d *updateData) ToSql() (sqlStr string, args []any, err error) {
 	sql := &strings.Builder{}
 
 	if len(d.Prefixes) > 0 {
-		args, err = appendToSql(d.Prefixes, sql, " ", args)
+		args, err = appendToSql(rc, d.Prefixes, sql, " ", args)
 		if err != nil {
 			return
 		}
@@ -47,35 +133,88 @@ func (d *updateData) ToSql() (sqlStr string, args []any, err error) {
 		sql.WriteString(" ")
 	}
 
+	if len(d.WithParts) > 0 {
+		args, err = appendWithToSql(rc, d.WithParts, sql, args)
+		if err != nil {
+			return
+		}
+	}
+
+	top, limit, err := rc.updateLimit(d.Limit, d.Offset, len(d.OrderBys) > 0)
+	if err != nil {
+		return
+	}
+	if len(d.Joins) > 0 && (len(d.OrderBys) > 0 || len(limit) > 0) {
+		// MySQL doesn't allow them in multiple-table statements.
+		err = fmt.Errorf("ORDER BY and LIMIT cannot be used with joins in UPDATE statements")
+		return
+	}
+
+	// Joins follow the FROM clause if there is one. Otherwise they follow the
+	// table (MySQL), or the table is repeated in a FROM clause and the alias
+	// is updated (SQL Server).
+	table := d.Table
+	joinTable, joinFrom := false, false
+	if len(d.Joins) > 0 && d.From == nil {
+		switch {
+		case rc.dialect == nil || rc.dialect.Supports(FeatureUpdateJoin):
+			joinTable = true
+		case rc.dialect.Supports(FeatureUpdateFromJoin):
+			joinFrom = true
+			table = tableAlias(d.Table)
+		default:
+			err = rc.require(FeatureUpdateJoin)
+			return
+		}
+	}
+
 	sql.WriteString("UPDATE ")
-	sql.WriteString(d.Table)
+	sql.WriteString(top)
+	sql.WriteString(table)
 
-	sql.WriteString(" SET ")
-	setSqls := make([]string, len(d.SetClauses))
-	for i, setClause := range d.SetClauses {
-		var valSql string
-		if vs, ok := setClause.value.(Sqlizer); ok {
-			vsql, vargs, err := nestedToSql(vs)
-			if err != nil {
-				return "", nil, err
-			}
-			if _, ok := vs.(SelectBuilder); ok {
-				valSql = fmt.Sprintf("(%s)", vsql)
-			} else {
-				valSql = vsql
-			}
-			args = append(args, vargs...)
-		} else {
-			valSql = "?"
-			args = append(args, setClause.value)
+	if joinTable {
+		sql.WriteString(" ")
+		args, err = appendToSql(rc, d.Joins, sql, " ", args)
+		if err != nil {
+			return
 		}
-		setSqls[i] = fmt.Sprintf("%s = %s", setClause.column, valSql)
 	}
-	sql.WriteString(strings.Join(setSqls, ", "))
+
+	sql.WriteString(" SET ")
+	args, err = appendSetClauses(rc, d.SetClauses, sql, args)
+	if err != nil {
+		return
+	}
+
+	returning, returningArgs, err := returningToSql(rc, d.Returning, "INSERTED")
+	if err != nil {
+		return
+	}
+	output := rc.useOutput()
+	if output && len(returning) > 0 {
+		sql.WriteString(" ")
+		sql.WriteString(returning)
+		args = append(args, returningArgs...)
+	}
 
 	if d.From != nil {
+		if err = rc.require(FeatureUpdateFrom); err != nil {
+			return
+		}
+
 		sql.WriteString(" FROM ")
-		args, err = appendToSql([]Sqlizer{d.From}, sql, "", args)
+		args, err = appendToSql(rc, []Sqlizer{d.From}, sql, "", args)
+		if err != nil {
+			return
+		}
+	} else if joinFrom {
+		sql.WriteString(" FROM ")
+		sql.WriteString(d.Table)
+	}
+
+	if len(d.Joins) > 0 && !joinTable {
+		sql.WriteString(" ")
+		args, err = appendToSql(rc, d.Joins, sql, " ", args)
 		if err != nil {
 			return
 		}
@@ -83,36 +222,34 @@ func (d *updateData) ToSql() (sqlStr string, args []any, err error) {
 
 	if len(d.WhereParts) > 0 {
 		sql.WriteString(" WHERE ")
-		args, err = appendToSql(d.WhereParts, sql, " AND ", args)
+		args, err = appendToSql(rc, d.WhereParts, sql, " AND ", args)
 		if err != nil {
 			return
 		}
 	}
 
+	if !output && len(returning) > 0 {
+		sql.WriteString(" ")
+		sql.WriteString(returning)
+		args = append(args, returningArgs...)
+	}
+
 	if len(d.OrderBys) > 0 {
 		sql.WriteString(" ORDER BY ")
 		sql.WriteString(strings.Join(d.OrderBys, ", "))
 	}
 
-	if len(d.Limit) > 0 {
-		sql.WriteString(" LIMIT ")
-		sql.WriteString(d.Limit)
-	}
-
-	if len(d.Offset) > 0 {
-		sql.WriteString(" OFFSET ")
-		sql.WriteString(d.Offset)
-	}
+	sql.WriteString(limit)
 
 	if len(d.Suffixes) > 0 {
 		sql.WriteString(" ")
-		args, err = appendToSql(d.Suffixes, sql, " ", args)
+		args, err = appendToSql(rc, d.Suffixes, sql, " ", args)
 		if err != nil {
 			return
 		}
 	}
 
-	sqlStr, err = d.PlaceholderFormat.ReplacePlaceholders(sql.String())
+	sqlStr = sql.String()
 	return
 }
 
@@ -133,6 +270,45 @@ func (b UpdateBuilder) PlaceholderFormat(f PlaceholderFormat) UpdateBuilder {
 	return builder.Set(b, "PlaceholderFormat", f).(UpdateBuilder)
 }
 
+// Dialect sets the Dialect (e.g. Postgres or MySQL) for the query, and sets the
+// PlaceholderFormat to the dialect's default. A nil Dialect clears it, keeping
+// the PlaceholderFormat.
+func (b UpdateBuilder) Dialect(d Dialect) UpdateBuilder {
+	if d == nil {
+		return builder.Delete(b, "Dialect").(UpdateBuilder)
+	}
+	b = builder.Set(b, "Dialect", d).(UpdateBuilder)
+	return b.PlaceholderFormat(d.PlaceholderFormat())
+}
+
+// Runner methods
+
+// RunWith sets a Runner (like database/sql.DB) to be used with e.g.
+// ExecContext.
+func (b UpdateBuilder) RunWith(runner Runner) UpdateBuilder {
+	return builder.Set(b, "RunWith", runner).(UpdateBuilder)
+}
+
+// ExecContext builds and ExecContexts the query with the Runner set by RunWith.
+func (b UpdateBuilder) ExecContext(ctx context.Context) (sql.Result, error) {
+	data := builder.GetStruct(b).(updateData)
+	return data.ExecContext(ctx)
+}
+
+// QueryContext builds and QueryContexts the query with the Runner set by
+// RunWith.
+func (b UpdateBuilder) QueryContext(ctx context.Context) (*sql.Rows, error) {
+	data := builder.GetStruct(b).(updateData)
+	return data.QueryContext(ctx)
+}
+
+// QueryRowContext builds and QueryRowContexts the query with the Runner set by
+// RunWith.
+func (b UpdateBuilder) QueryRowContext(ctx context.Context) RowScanner {
+	data := builder.GetStruct(b).(updateData)
+	return data.QueryRowContext(ctx)
+}
+
 // SQL methods
 
 // ToSql builds the query into a SQL string and bound args.
@@ -141,6 +317,43 @@ func (b UpdateBuilder) ToSql() (string, []any, error) {
 	return data.ToSql()
 }
 
+// ToSqlRaw builds the query into a SQL string and bound args without
+// replacing the placeholders, for use in nested queries.
+func (b UpdateBuilder) ToSqlRaw() (string, []any, error) {
+	data := builder.GetStruct(b).(updateData)
+	return data.ToSqlRaw()
+}
+
+// toSqlContext builds a nested query, inheriting the Dialect of the enclosing
+// statement if none is set.
+func (b UpdateBuilder) toSqlContext(rc renderContext) (string, []any, error) {
+	data := builder.GetStruct(b).(updateData)
+	if data.Dialect != nil {
+		rc.dialect = data.Dialect
+	}
+	return data.toSqlRaw(rc)
+}
+
+// With adds a common table expression to the WITH clause of the query.
+//
+// See WithBuilder.As.
+func (b UpdateBuilder) With(alias string, sql Sqlizer, opts ...CTEOption) UpdateBuilder {
+	return builder.Append(b, "WithParts", newWithPart(alias, sql, opts)).(UpdateBuilder)
+}
+
+// WithRecursive adds a recursive common table expression to the WITH clause
+// of the query.
+//
+// See WithBuilder.Recursive.
+func (b UpdateBuilder) WithRecursive(alias string, anchor, recursive SelectBuilder, opts ...CTEOption) UpdateBuilder {
+	return builder.Append(b, "WithParts", newRecursiveWithPart(alias, anchor, recursive, opts)).(UpdateBuilder)
+}
+
+// RemoveWith removes the WITH clause.
+func (b UpdateBuilder) RemoveWith() UpdateBuilder {
+	return builder.Delete(b, "WithParts").(UpdateBuilder)
+}
+
 // Prefix adds an expression to the beginning of the query
 func (b UpdateBuilder) Prefix(sql string, args ...any) UpdateBuilder {
 	return b.PrefixExpr(Expr(sql, args...))
@@ -177,17 +390,115 @@ func (b UpdateBuilder) SetMap(clauses map[string]any) UpdateBuilder {
 	return b
 }
 
+// SetStruct is a convenience method which calls .Set for each db tagged field
+// of a struct (or pointer to struct), in declaration order.
+//
+// Fields tagged readonly or pk are skipped, as are fields tagged omitempty
+// which have their zero value. Use Where to restrict the rows to update. See
+// Get for how fields are mapped to columns.
+//
+// SetStruct panics if v is not a struct or a pointer to a struct.
+func (b UpdateBuilder) SetStruct(v any) UpdateBuilder {
+	rv, info := mustStructValue(v)
+	cols, vals := info.setColumns(rv, true, true)
+	for i, col := range cols {
+		b = b.Set(col, vals[i])
+	}
+	return b
+}
+
 // From adds FROM clause to the query
 // FROM is valid construct in postgresql only.
 func (b UpdateBuilder) From(from string) UpdateBuilder {
 	return builder.Set(b, "From", newPart(from)).(UpdateBuilder)
 }
 
-// FromSelect sets a subquery into the FROM clause of the query.
+// FromSelect sets a subquery into the FROM clause of the query. The alias
+// follows AS, except for dialects which don't accept it, such as Oracle.
 func (b UpdateBuilder) FromSelect(from SelectBuilder, alias string) UpdateBuilder {
 	// Prevent misnumbered parameters in nested selects (#183).
 	from = from.PlaceholderFormat(Question)
-	return builder.Set(b, "From", Alias(from, alias)).(UpdateBuilder)
+	return builder.Set(b, "From", derivedTable(from, alias)).(UpdateBuilder)
+}
+
+// JoinClause adds a join clause to the query.
+//
+// If a FROM clause is set, joins are rendered after it, as PostgreSQL and
+// SQLite require (the target table is then joined in the WHERE clause).
+// Otherwise they join the target table directly, as "UPDATE a JOIN b ON ...
+// SET ..." for MySQL or "UPDATE a SET ... FROM a JOIN b ON ..." for SQL Server.
+func (b UpdateBuilder) JoinClause(pred any, args ...any) UpdateBuilder {
+	return builder.Append(b, "Joins", newPart(pred, args...)).(UpdateBuilder)
+}
+
+// RemoveJoins removes JOIN clauses.
+func (b UpdateBuilder) RemoveJoins() UpdateBuilder {
+	return builder.Delete(b, "Joins").(UpdateBuilder)
+}
+
+// Join adds a JOIN clause to the query.
+func (b UpdateBuilder) Join(join string, rest ...any) UpdateBuilder {
+	return b.JoinClause("JOIN "+join, rest...)
+}
+
+// LeftJoin adds a LEFT JOIN clause to the query.
+func (b UpdateBuilder) LeftJoin(join string, rest ...any) UpdateBuilder {
+	return b.JoinClause("LEFT JOIN "+join, rest...)
+}
+
+// RightJoin adds a RIGHT JOIN clause to the query.
+func (b UpdateBuilder) RightJoin(join string, rest ...any) UpdateBuilder {
+	return b.JoinClause("RIGHT JOIN "+join, rest...)
+}
+
+// InnerJoin adds a INNER JOIN clause to the query.
+func (b UpdateBuilder) InnerJoin(join string, rest ...any) UpdateBuilder {
+	return b.JoinClause("INNER JOIN "+join, rest...)
+}
+
+// CrossJoin adds a CROSS JOIN clause to the query.
+func (b UpdateBuilder) CrossJoin(join string, rest ...any) UpdateBuilder {
+	return b.JoinClause("CROSS JOIN "+join, rest...)
+}
+
+// FullJoin adds a FULL JOIN clause to the query.
+func (b UpdateBuilder) FullJoin(join string, rest ...any) UpdateBuilder {
+	return b.JoinClause("FULL JOIN "+join, rest...)
+}
+
+// joinOn adds a join on clause to the query,
+func (b UpdateBuilder) joinOn(prefix string, join any, on Sqlizer) UpdateBuilder {
+	return b.JoinClause(ConcatExpr(prefix, " ", join, " ON ", on))
+}
+
+// JoinOn adds a JOIN ON clause to the query.
+func (b UpdateBuilder) JoinOn(join any, on Sqlizer) UpdateBuilder {
+	return b.joinOn("JOIN", join, on)
+}
+
+// LeftJoinOn adds a LEFT JOIN ON clause to the query.
+func (b UpdateBuilder) LeftJoinOn(join any, on Sqlizer) UpdateBuilder {
+	return b.joinOn("LEFT JOIN", join, on)
+}
+
+// RightJoinOn adds a RIGHT JOIN ON clause to the query.
+func (b UpdateBuilder) RightJoinOn(join any, on Sqlizer) UpdateBuilder {
+	return b.joinOn("RIGHT JOIN", join, on)
+}
+
+// InnerJoinOn adds a INNER JOIN ON clause to the query.
+func (b UpdateBuilder) InnerJoinOn(join any, on Sqlizer) UpdateBuilder {
+	return b.joinOn("INNER JOIN", join, on)
+}
+
+// CrossJoinOn adds a CROSS JOIN ON clause to the query.
+func (b UpdateBuilder) CrossJoinOn(join any, on Sqlizer) UpdateBuilder {
+	return b.joinOn("CROSS JOIN", join, on)
+}
+
+// FullJoinOn adds a FULL JOIN ON clause to the query.
+func (b UpdateBuilder) FullJoinOn(join any, on Sqlizer) UpdateBuilder {
+	return b.joinOn("FULL JOIN", join, on)
 }
 
 // Where adds WHERE expressions to the query.
@@ -203,6 +514,9 @@ func (b UpdateBuilder) OrderBy(orderBys ...string) UpdateBuilder {
 }
 
 // Limit sets a LIMIT clause on the query.
+//
+// Depending on the Dialect (or, if none is set, the PlaceholderFormat), the
+// limit is rendered as LIMIT n or TOP (n).
 func (b UpdateBuilder) Limit(limit uint64) UpdateBuilder {
 	return builder.Set(b, "Limit", fmt.Sprintf("%d", limit)).(UpdateBuilder)
 }
@@ -221,3 +535,15 @@ func (b UpdateBuilder) Suffix(sql string, args ...any) UpdateBuilder {
 func (b UpdateBuilder) SuffixExpr(expr Sqlizer) UpdateBuilder {
 	return builder.Append(b, "Suffixes", expr).(UpdateBuilder)
 }
+
+// Returning adds columns to the RETURNING clause of the query, or for SQL
+// Server the OUTPUT clause, where they are qualified with INSERTED.
+func (b UpdateBuilder) Returning(columns ...string) UpdateBuilder {
+	return builder.Extend(b, "Returning", returningColumns(columns)).(UpdateBuilder)
+}
+
+// ReturningExpr adds an expression to the RETURNING (or OUTPUT) clause of the
+// query.
+func (b UpdateBuilder) ReturningExpr(expr Sqlizer) UpdateBuilder {
+	return builder.Append(b, "Returning", returningPart{expr: expr}).(UpdateBuilder)
+}
@@ -79,3 +79,57 @@ func TestUpdateBuilderFromSelect(t *testing.T) {
 			"WHERE employees.account_id = subquery.id"
 	require.Equal(t, expectedSql, sql)
 }
+
+func TestUpdateBuilderSetStruct(t *testing.T) {
+	sql, args, err := Update("users").
+		SetStruct(structTestUser{ID: 1, Email: "a@example.com"}).
+		Where(Eq{"id": 1}).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "UPDATE users SET email = ? WHERE id = ?", sql)
+	require.Equal(t, []any{"a@example.com", 1}, args)
+
+	require.Panics(t, func() { Update("users").SetStruct("x") })
+}
+
+func TestUpdateBuilderJoins(t *testing.T) {
+	b := Update("users u").
+		Set("u.plan", Expr("o.plan")).
+		JoinOn("orgs o", Expr("o.id = u.org_id AND o.active = ?", true)).
+		Where(Eq{"o.id": 1})
+
+	sql, args, err := b.Dialect(MySQL).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "UPDATE users u JOIN orgs o ON o.id = u.org_id AND o.active = ? SET u.plan = o.plan WHERE o.id = ?", sql)
+	require.Equal(t, []any{true, 1}, args)
+
+	sql, args, err = b.Returning("id").Dialect(SQLServer).ToSql()
+	require.NoError(t, err)
+	require.Equal(t,
+		"UPDATE u SET u.plan = o.plan OUTPUT INSERTED.id FROM users u JOIN orgs o ON o.id = u.org_id AND o.active = @p1 WHERE o.id = @p2",
+		sql)
+	require.Equal(t, []any{true, 1}, args)
+
+	_, _, err = b.Dialect(Postgres).ToSql()
+	require.EqualError(t, err, "UPDATE ... JOIN is not supported by PostgreSQL")
+
+	sql, _, err = Update("users u").
+		Set("plan", Expr("o.plan")).
+		From("orgs o").
+		LeftJoin("plans p ON p.id = o.plan_id").
+		Where("o.id = u.org_id").
+		Dialect(Postgres).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "UPDATE users u SET plan = o.plan FROM orgs o LEFT JOIN plans p ON p.id = o.plan_id WHERE o.id = u.org_id", sql)
+
+	sql, _, err = b.RemoveJoins().ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "UPDATE users u SET u.plan = o.plan WHERE o.id = ?", sql)
+
+	_, _, err = b.OrderBy("u.id").Dialect(MySQL).ToSql()
+	require.EqualError(t, err, "ORDER BY and LIMIT cannot be used with joins in UPDATE statements")
+
+	_, _, err = b.Limit(10).Dialect(MySQL).ToSql()
+	require.EqualError(t, err, "ORDER BY and LIMIT cannot be used with joins in UPDATE statements")
+}
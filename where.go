@@ -7,19 +7,24 @@ import (
 type wherePart part
 
 func newWherePart(pred any, args ...any) Sqlizer {
+	if named := namedPart(pred, args); named != nil {
+		return named
+	}
 	return &wherePart{pred: pred, args: args}
 }
 
 func (p wherePart) ToSql() (sql string, args []any, err error) {
+	return p.toSqlContext(renderContext{})
+}
+
+func (p wherePart) toSqlContext(rc renderContext) (sql string, args []any, err error) {
 	switch pred := p.pred.(type) {
 	case nil:
 		// no-op
-	case RawSqlizer:
-		return pred.ToSqlRaw()
 	case Sqlizer:
-		return pred.ToSql()
+		return nestedToSql(rc, pred)
 	case map[string]any:
-		return Eq(pred).ToSql()
+		return Eq(pred).toSqlContext(rc)
 	case string:
 		sql = pred
 		args = p.args
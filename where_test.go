@@ -14,14 +14,14 @@ func TestWherePartsAppendToSql(t *testing.T) {
 		newWherePart(Eq{"y": 2}),
 	}
 	var sql strings.Builder
-	args, _ := appendToSql(parts, &sql, " AND ", []any{})
+	args, _ := appendToSql(renderContext{}, parts, &sql, " AND ", []any{})
 	require.Equal(t, "x = ? AND y = ?", sql.String())
 	require.Equal(t, []any{1, 2}, args)
 }
 
 func TestWherePartsAppendToSqlErr(t *testing.T) {
 	parts := []Sqlizer{newWherePart(1)}
-	_, err := appendToSql(parts, &strings.Builder{}, "", []any{})
+	_, err := appendToSql(renderContext{}, parts, &strings.Builder{}, "", []any{})
 	require.Error(t, err)
 }
 
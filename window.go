@@ -0,0 +1,379 @@
+package sq
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FrameBound is the start or end of a window frame, e.g. UnboundedPreceding
+// or Preceding(3).
+type FrameBound string
+
+const (
+	// UnboundedPreceding is the first row of the partition.
+	UnboundedPreceding FrameBound = "UNBOUNDED PRECEDING"
+
+	// CurrentRow is the current row (or, for RANGE and GROUPS, its peers).
+	CurrentRow FrameBound = "CURRENT ROW"
+
+	// UnboundedFollowing is the last row of the partition.
+	UnboundedFollowing FrameBound = "UNBOUNDED FOLLOWING"
+)
+
+// Preceding returns the frame bound offset rows (or, for RANGE, values and
+// GROUPS, peer groups) before the current row. offset is rendered as is, e.g.
+// Preceding(3) or Preceding("INTERVAL '1 day'").
+func Preceding(offset any) FrameBound {
+	return FrameBound(fmt.Sprintf("%v PRECEDING", offset))
+}
+
+// Following returns the frame bound offset rows (or, for RANGE, values and
+// GROUPS, peer groups) after the current row. See Preceding.
+func Following(offset any) FrameBound {
+	return FrameBound(fmt.Sprintf("%v FOLLOWING", offset))
+}
+
+// FrameExclusion is the EXCLUDE option of a window frame.
+type FrameExclusion string
+
+const (
+	ExcludeCurrentRow FrameExclusion = "EXCLUDE CURRENT ROW"
+	ExcludeGroup      FrameExclusion = "EXCLUDE GROUP"
+	ExcludeTies       FrameExclusion = "EXCLUDE TIES"
+	ExcludeNoOthers   FrameExclusion = "EXCLUDE NO OTHERS"
+)
+
+// WindowSpec is a window specification built by Over. Like the builders, it
+// is immutable: each method returns a modified copy.
+type WindowSpec struct {
+	base        string
+	partitionBy []Sqlizer
+	orderBy     []Sqlizer
+	frameUnits  string
+	frameStart  FrameBound
+	frameEnd    FrameBound
+	exclude     FrameExclusion
+}
+
+// Over returns an empty window specification, which covers every row of the
+// result.
+//
+// Ex:
+//
+//	RowNumber().Over(Over().PartitionBy("org_id").OrderBy("created_at DESC"))
+//	// ROW_NUMBER() OVER (PARTITION BY org_id ORDER BY created_at DESC)
+func Over() WindowSpec {
+	return WindowSpec{}
+}
+
+// Base sets the named window (see SelectBuilder.Window) which the
+// specification extends.
+func (w WindowSpec) Base(name string) WindowSpec {
+	w.base = name
+	return w
+}
+
+// PartitionBy adds PARTITION BY expressions to the specification.
+func (w WindowSpec) PartitionBy(exprs ...string) WindowSpec {
+	w.partitionBy = appendParts(w.partitionBy, exprs)
+	return w
+}
+
+// PartitionByExpr adds a PARTITION BY expression with args to the
+// specification.
+func (w WindowSpec) PartitionByExpr(expr Sqlizer) WindowSpec {
+	w.partitionBy = append(w.partitionBy[:len(w.partitionBy):len(w.partitionBy)], expr)
+	return w
+}
+
+// OrderBy adds ORDER BY expressions to the specification.
+func (w WindowSpec) OrderBy(exprs ...string) WindowSpec {
+	w.orderBy = appendParts(w.orderBy, exprs)
+	return w
+}
+
+// OrderByExpr adds an ORDER BY expression with args to the specification.
+func (w WindowSpec) OrderByExpr(expr Sqlizer) WindowSpec {
+	w.orderBy = append(w.orderBy[:len(w.orderBy):len(w.orderBy)], expr)
+	return w
+}
+
+// Rows sets a ROWS frame. If end is empty, the frame ends at the current row.
+//
+// Ex:
+//
+//	Over().OrderBy("day").Rows(Preceding(6), CurrentRow)
+//	// ORDER BY day ROWS BETWEEN 6 PRECEDING AND CURRENT ROW
+func (w WindowSpec) Rows(start, end FrameBound) WindowSpec {
+	return w.frame("ROWS", start, end)
+}
+
+// Range sets a RANGE frame. See Rows.
+func (w WindowSpec) Range(start, end FrameBound) WindowSpec {
+	return w.frame("RANGE", start, end)
+}
+
+// Groups sets a GROUPS frame. See Rows.
+func (w WindowSpec) Groups(start, end FrameBound) WindowSpec {
+	return w.frame("GROUPS", start, end)
+}
+
+func (w WindowSpec) frame(units string, start, end FrameBound) WindowSpec {
+	w.frameUnits = units
+	w.frameStart = start
+	w.frameEnd = end
+	return w
+}
+
+// Exclude sets the EXCLUDE option of the frame.
+func (w WindowSpec) Exclude(e FrameExclusion) WindowSpec {
+	w.exclude = e
+	return w
+}
+
+// ToSql builds the specification, without the enclosing parentheses.
+func (w WindowSpec) ToSql() (string, []any, error) {
+	return w.toSqlContext(renderContext{})
+}
+
+func (w WindowSpec) toSqlContext(rc renderContext) (sqlStr string, args []any, err error) {
+	sql := &strings.Builder{}
+	sep := ""
+
+	if len(w.base) > 0 {
+		sql.WriteString(w.base)
+		sep = " "
+	}
+
+	if len(w.partitionBy) > 0 {
+		sql.WriteString(sep)
+		sql.WriteString("PARTITION BY ")
+		args, err = appendToSql(rc, w.partitionBy, sql, ", ", args)
+		if err != nil {
+			return
+		}
+		sep = " "
+	}
+
+	if len(w.orderBy) > 0 {
+		sql.WriteString(sep)
+		sql.WriteString("ORDER BY ")
+		args, err = appendToSql(rc, w.orderBy, sql, ", ", args)
+		if err != nil {
+			return
+		}
+		sep = " "
+	}
+
+	if len(w.frameUnits) > 0 {
+		if w.frameUnits == "GROUPS" {
+			if err = rc.require(FeatureFrameGroups); err != nil {
+				return
+			}
+		}
+		sql.WriteString(sep)
+		sql.WriteString(w.frameUnits)
+		if len(w.frameEnd) > 0 {
+			fmt.Fprintf(sql, " BETWEEN %s AND %s", w.frameStart, w.frameEnd)
+		} else {
+			sql.WriteString(" ")
+			sql.WriteString(string(w.frameStart))
+		}
+		sep = " "
+	}
+
+	if len(w.exclude) > 0 {
+		if len(w.frameUnits) == 0 {
+			err = fmt.Errorf("%s requires a frame", w.exclude)
+			return
+		}
+		if err = rc.require(FeatureFrameExclude); err != nil {
+			return
+		}
+		sql.WriteString(sep)
+		sql.WriteString(string(w.exclude))
+	}
+
+	sqlStr = sql.String()
+	return
+}
+
+func appendParts(parts []Sqlizer, exprs []string) []Sqlizer {
+	// copy so that specs sharing a backing array don't clobber each other
+	parts = parts[:len(parts):len(parts)]
+	for _, expr := range exprs {
+		parts = append(parts, newPart(expr))
+	}
+	return parts
+}
+
+// namedWindow is a "name AS (spec)" part of the WINDOW clause.
+type namedWindow struct {
+	name string
+	spec WindowSpec
+}
+
+func (w namedWindow) ToSql() (string, []any, error) {
+	return w.toSqlContext(renderContext{})
+}
+
+func (w namedWindow) toSqlContext(rc renderContext) (string, []any, error) {
+	sql, args, err := w.spec.toSqlContext(rc)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("%s AS (%s)", w.name, sql), args, nil
+}
+
+// WindowFunc is a window function or aggregate call, built by e.g. RowNumber
+// or Agg. Like the builders, it is immutable: each method returns a modified
+// copy.
+type WindowFunc struct {
+	fn       Sqlizer
+	filter   []Sqlizer
+	over     *WindowSpec
+	overName string
+}
+
+// Agg returns a WindowFunc for an aggregate function call, which may be given
+// a FILTER clause or used as a window function.
+//
+// Ex:
+//
+//	Agg("COUNT(*)").Filter(Eq{"status": "paid"})
+//	// COUNT(*) FILTER (WHERE status = ?)
+func Agg(sql string, args ...any) WindowFunc {
+	return WindowFunc{fn: Expr(sql, args...)}
+}
+
+// RowNumber returns a ROW_NUMBER() window function.
+func RowNumber() WindowFunc {
+	return Agg("ROW_NUMBER()")
+}
+
+// Rank returns a RANK() window function.
+func Rank() WindowFunc {
+	return Agg("RANK()")
+}
+
+// DenseRank returns a DENSE_RANK() window function.
+func DenseRank() WindowFunc {
+	return Agg("DENSE_RANK()")
+}
+
+// PercentRank returns a PERCENT_RANK() window function.
+func PercentRank() WindowFunc {
+	return Agg("PERCENT_RANK()")
+}
+
+// CumeDist returns a CUME_DIST() window function.
+func CumeDist() WindowFunc {
+	return Agg("CUME_DIST()")
+}
+
+// Ntile returns an NTILE(n) window function.
+func Ntile(n int) WindowFunc {
+	return Agg(fmt.Sprintf("NTILE(%d)", n))
+}
+
+// Lag returns a LAG(expr, offset) window function.
+func Lag(expr string, offset int) WindowFunc {
+	return Agg(fmt.Sprintf("LAG(%s, %d)", expr, offset))
+}
+
+// Lead returns a LEAD(expr, offset) window function.
+func Lead(expr string, offset int) WindowFunc {
+	return Agg(fmt.Sprintf("LEAD(%s, %d)", expr, offset))
+}
+
+// FirstValue returns a FIRST_VALUE(expr) window function.
+func FirstValue(expr string) WindowFunc {
+	return Agg(fmt.Sprintf("FIRST_VALUE(%s)", expr))
+}
+
+// LastValue returns a LAST_VALUE(expr) window function.
+func LastValue(expr string) WindowFunc {
+	return Agg(fmt.Sprintf("LAST_VALUE(%s)", expr))
+}
+
+// NthValue returns an NTH_VALUE(expr, n) window function.
+func NthValue(expr string, n int) WindowFunc {
+	return Agg(fmt.Sprintf("NTH_VALUE(%s, %d)", expr, n))
+}
+
+// Filter adds an expression to the FILTER (WHERE ...) clause of an aggregate.
+// Expressions are ANDed together.
+//
+// See SelectBuilder.Where for the accepted types of pred.
+func (f WindowFunc) Filter(pred any, args ...any) WindowFunc {
+	if pred == nil || pred == "" {
+		return f
+	}
+	f.filter = append(f.filter[:len(f.filter):len(f.filter)], newWherePart(pred, args...))
+	return f
+}
+
+// Over sets the window specification of the function.
+func (f WindowFunc) Over(spec WindowSpec) WindowFunc {
+	f.over = &spec
+	f.overName = ""
+	return f
+}
+
+// OverWindow sets the window of the function to a named window (see
+// SelectBuilder.Window).
+func (f WindowFunc) OverWindow(name string) WindowFunc {
+	f.over = nil
+	f.overName = name
+	return f
+}
+
+// As returns the function call with an alias, for use as a result column.
+func (f WindowFunc) As(alias string) Sqlizer {
+	return ConcatExpr(f, " AS "+alias)
+}
+
+func (f WindowFunc) ToSql() (string, []any, error) {
+	return f.toSqlContext(renderContext{})
+}
+
+func (f WindowFunc) toSqlContext(rc renderContext) (sqlStr string, args []any, err error) {
+	sql := &strings.Builder{}
+
+	args, err = appendToSql(rc, []Sqlizer{f.fn}, sql, "", args)
+	if err != nil {
+		return
+	}
+
+	if len(f.filter) > 0 {
+		filter := &strings.Builder{}
+		args, err = appendToSql(rc, f.filter, filter, " AND ", args)
+		if err != nil {
+			return
+		}
+		if filter.Len() > 0 {
+			if err = rc.require(FeatureFilter); err != nil {
+				return
+			}
+			fmt.Fprintf(sql, " FILTER (WHERE %s)", filter)
+		}
+	}
+
+	switch {
+	case f.over != nil:
+		var specSql string
+		var specArgs []any
+		specSql, specArgs, err = f.over.toSqlContext(rc)
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(sql, " OVER (%s)", specSql)
+		args = append(args, specArgs...)
+	case len(f.overName) > 0:
+		sql.WriteString(" OVER ")
+		sql.WriteString(f.overName)
+	}
+
+	sqlStr = sql.String()
+	return
+}
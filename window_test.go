@@ -0,0 +1,113 @@
+package sq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowFunc(t *testing.T) {
+	sql, args, err := Select("id").
+		Column(RowNumber().Over(Over().PartitionBy("org_id").OrderBy("created_at DESC")).As("rn")).
+		Column(Agg("SUM(amount)").Over(Over().OrderBy("day").Rows(Preceding(6), CurrentRow))).
+		Column(Agg("COUNT(*)").Filter(Eq{"status": "paid"}).Filter("amount > ?", 10)).
+		From("orders").
+		Where(Eq{"org_id": 1}).
+		PlaceholderFormat(Dollar).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t,
+		"SELECT id, ROW_NUMBER() OVER (PARTITION BY org_id ORDER BY created_at DESC) AS rn, "+
+			"SUM(amount) OVER (ORDER BY day ROWS BETWEEN 6 PRECEDING AND CURRENT ROW), "+
+			"COUNT(*) FILTER (WHERE status = $1 AND amount > $2) "+
+			"FROM orders WHERE org_id = $3",
+		sql)
+	require.Equal(t, []any{"paid", 10, 1}, args)
+}
+
+func TestWindowSpec(t *testing.T) {
+	tests := []struct {
+		spec WindowSpec
+		sql  string
+	}{
+		{Over(), ""},
+		{Over().Base("w").OrderBy("a"), "w ORDER BY a"},
+		{Over().OrderBy("a").Range(UnboundedPreceding, ""), "ORDER BY a RANGE UNBOUNDED PRECEDING"},
+		{
+			Over().OrderBy("a").Groups(Preceding(1), Following(1)).Exclude(ExcludeCurrentRow),
+			"ORDER BY a GROUPS BETWEEN 1 PRECEDING AND 1 FOLLOWING EXCLUDE CURRENT ROW",
+		},
+		{
+			Over().OrderBy("day").Range(Preceding("INTERVAL '7 days'"), UnboundedFollowing),
+			"ORDER BY day RANGE BETWEEN INTERVAL '7 days' PRECEDING AND UNBOUNDED FOLLOWING",
+		},
+	}
+
+	for _, tt := range tests {
+		sql, _, err := tt.spec.ToSql()
+		require.NoError(t, err)
+		require.Equal(t, tt.sql, sql)
+	}
+
+	sql, args, err := Over().PartitionByExpr(Expr("date_trunc(?, at)", "day")).OrderByExpr(Expr("a <-> ?", 3)).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "PARTITION BY date_trunc(?, at) ORDER BY a <-> ?", sql)
+	require.Equal(t, []any{"day", 3}, args)
+
+	_, _, err = Over().Exclude(ExcludeTies).ToSql()
+	require.EqualError(t, err, "EXCLUDE TIES requires a frame")
+}
+
+func TestWindowSpecImmutable(t *testing.T) {
+	base := Over().PartitionBy("a")
+	x := base.OrderBy("x")
+	y := base.OrderBy("y")
+
+	sql, _, _ := x.ToSql()
+	require.Equal(t, "PARTITION BY a ORDER BY x", sql)
+	sql, _, _ = y.ToSql()
+	require.Equal(t, "PARTITION BY a ORDER BY y", sql)
+	sql, _, _ = base.ToSql()
+	require.Equal(t, "PARTITION BY a", sql)
+}
+
+func TestSelectBuilderWindow(t *testing.T) {
+	sql, _, err := Select("id").
+		Column(Rank().OverWindow("w").As("rank")).
+		Column(Lag("score", 1).Over(Over().Base("w"))).
+		Column(Ntile(4).OverWindow("w")).
+		From("scores").
+		GroupBy("id", "game_id", "score").
+		Having("COUNT(*) > 1").
+		Window("w", Over().PartitionBy("game_id").OrderBy("score DESC")).
+		Window("w2", Over().Base("w").Rows(UnboundedPreceding, CurrentRow)).
+		OrderBy("id").
+		Limit(10).
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t,
+		"SELECT id, RANK() OVER w AS rank, LAG(score, 1) OVER (w), NTILE(4) OVER w FROM scores "+
+			"GROUP BY id, game_id, score HAVING COUNT(*) > 1 "+
+			"WINDOW w AS (PARTITION BY game_id ORDER BY score DESC), w2 AS (w ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) "+
+			"ORDER BY id LIMIT 10",
+		sql)
+}
+
+func TestWindowFilterEmpty(t *testing.T) {
+	sql, _, err := Agg("COUNT(*)").Filter("").Filter(nil).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "COUNT(*)", sql)
+
+	sql, _, err = Select().Column(Agg("COUNT(*)").Filter(Expr(""))).From("t").Dialect(MySQL).ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT COUNT(*) FROM t", sql)
+}
+
+func TestWindowDialect(t *testing.T) {
+	_, _, err := Select().Column(Agg("COUNT(*)").Filter("a")).From("t").Dialect(MySQL).ToSql()
+	require.EqualError(t, err, "FILTER is not supported by MySQL")
+
+	_, _, err = Select().Column(RowNumber().Over(Over().OrderBy("a").Groups(CurrentRow, ""))).From("t").
+		Dialect(SQLServer).ToSql()
+	require.EqualError(t, err, "GROUPS is not supported by SQL Server")
+}
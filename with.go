@@ -1,6 +1,10 @@
 package sq
 
 import (
+	"fmt"
+	"io"
+	"strings"
+
 	"github.com/userhubdev/sq/internal/builder"
 )
 
@@ -10,31 +14,118 @@ func init() {
 
 // withPart is a helper structure to describe the cte parts of a WITH clause.
 type withPart struct {
-	alias string
-	cte   Sqlizer
+	alias        string
+	cte          Sqlizer
+	columns      []string
+	materialized string
+	search       string
+	cycle        string
+	recursive    bool
 }
 
 // newWithPart creates a new withPart for a WITH clause.
-func newWithPart(alias string, cte Sqlizer) withPart {
-	return withPart{alias: alias, cte: cte}
+func newWithPart(alias string, cte Sqlizer, opts []CTEOption) withPart {
+	p := withPart{alias: alias, cte: cte}
+	for _, opt := range opts {
+		opt(&p)
+	}
+	return p
+}
+
+// newRecursiveWithPart creates a new recursive withPart for a WITH clause,
+// joining the anchor and recursive members with UNION ALL.
+func newRecursiveWithPart(alias string, anchor, recursive SelectBuilder, opts []CTEOption) withPart {
+	p := newWithPart(alias, UnionAll(anchor, recursive), opts)
+	p.recursive = true
+	return p
+}
+
+// CTEOption is an option of a common table expression added to a WITH clause
+// by WithBuilder.As or WithBuilder.Recursive, or by the With and WithRecursive
+// methods of the statement builders.
+type CTEOption func(*withPart)
+
+// CTEColumns names the columns of the common table expression, as in
+// "alias(col1, col2) AS (...)".
+func CTEColumns(columns ...string) CTEOption {
+	return func(p *withPart) {
+		p.columns = columns
+	}
+}
+
+// Materialized forces PostgreSQL to compute the common table expression once,
+// as in "alias AS MATERIALIZED (...)".
+func Materialized() CTEOption {
+	return func(p *withPart) {
+		p.materialized = "MATERIALIZED"
+	}
+}
+
+// NotMaterialized allows PostgreSQL to inline the common table expression
+// into the primary statement, as in "alias AS NOT MATERIALIZED (...)".
+func NotMaterialized() CTEOption {
+	return func(p *withPart) {
+		p.materialized = "NOT MATERIALIZED"
+	}
+}
+
+// SearchDepthFirst adds a PostgreSQL SEARCH DEPTH FIRST clause to a recursive
+// common table expression, which sets the column set to a value which orders
+// the rows depth first by the given columns.
+func SearchDepthFirst(set string, by ...string) CTEOption {
+	return func(p *withPart) {
+		p.search = fmt.Sprintf("SEARCH DEPTH FIRST BY %s SET %s", strings.Join(by, ", "), set)
+	}
+}
+
+// SearchBreadthFirst is like SearchDepthFirst, but orders the rows breadth
+// first.
+func SearchBreadthFirst(set string, by ...string) CTEOption {
+	return func(p *withPart) {
+		p.search = fmt.Sprintf("SEARCH BREADTH FIRST BY %s SET %s", strings.Join(by, ", "), set)
+	}
+}
+
+// Cycle adds a PostgreSQL CYCLE clause to a recursive common table
+// expression, which stops the recursion when the given columns repeat. The
+// column set is set to whether a cycle was found, and the column using to the
+// path of rows visited.
+func Cycle(set, using string, columns ...string) CTEOption {
+	return func(p *withPart) {
+		p.cycle = fmt.Sprintf("CYCLE %s SET %s USING %s", strings.Join(columns, ", "), set, using)
+	}
 }
 
 // withData holds all the data required to build a WITH clause.
 type withData struct {
 	PlaceholderFormat PlaceholderFormat
+	Dialect           Dialect
+	RunWith           Runner
+	WhereParts        []Sqlizer
 	WithParts         []withPart
 }
 
 // ToSql implements Sqlizer.
 func (d *withData) ToSql() (sqlStr string, args []any, err error) {
+	return d.toSqlContext(renderContext{dialect: d.Dialect, format: d.PlaceholderFormat})
+}
+
+func (d *withData) toSqlContext(rc renderContext) (sqlStr string, args []any, err error) {
 	if len(d.WithParts) == 0 {
 		return "", nil, nil
 	}
 
-	sql := Builder{}
+	sql := Builder{rc: rc}
 
 	sql.WriteString("WITH")
 
+	for _, p := range d.WithParts {
+		if p.recursive && (rc.dialect == nil || rc.dialect.Supports(FeatureRecursiveKeyword)) {
+			sql.WriteString(" RECURSIVE")
+			break
+		}
+	}
+
 	for i, p := range d.WithParts {
 		if i > 0 {
 			sql.WriteString(", ")
@@ -42,14 +133,50 @@ func (d *withData) ToSql() (sqlStr string, args []any, err error) {
 			sql.WriteString(" ")
 		}
 		sql.WriteString(p.alias)
-		sql.WriteString(" AS (")
+		if len(p.columns) > 0 {
+			sql.WriteString("(")
+			sql.WriteString(strings.Join(p.columns, ", "))
+			sql.WriteString(")")
+		}
+		sql.WriteString(" AS ")
+		if len(p.materialized) > 0 {
+			if err = rc.require(FeatureCTEMaterialized); err != nil {
+				return
+			}
+			sql.WriteString(p.materialized)
+			sql.WriteString(" ")
+		}
+		sql.WriteString("(")
 		sql.WriteSql(p.cte)
 		sql.WriteString(")")
+		for _, clause := range []string{p.search, p.cycle} {
+			if len(clause) == 0 {
+				continue
+			}
+			if err = rc.require(FeatureCTESearchCycle); err != nil {
+				return
+			}
+			sql.WriteString(" ")
+			sql.WriteString(clause)
+		}
 	}
 
 	return sql.ToSql()
 }
 
+// appendWithToSql writes the WITH clause of a statement, followed by a space.
+func appendWithToSql(rc renderContext, parts []withPart, w io.Writer, args []any) ([]any, error) {
+	sql, withArgs, err := (&withData{WithParts: parts}).toSqlContext(rc)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.WriteString(w, sql+" ")
+	if err != nil {
+		return nil, err
+	}
+	return append(args, withArgs...), nil
+}
+
 // WithBuilder builds a WITH clause.
 type WithBuilder builder.Builder
 
@@ -59,9 +186,36 @@ func (b WithBuilder) ToSql() (string, []any, error) {
 	return data.ToSql()
 }
 
+func (b WithBuilder) toSqlContext(rc renderContext) (string, []any, error) {
+	data := builder.GetStruct(b).(withData)
+	return data.toSqlContext(rc)
+}
+
 // As adds a "... AS (...)" part to the WITH clause.
-func (b WithBuilder) As(alias string, sql Sqlizer) WithBuilder {
-	return builder.Append(b, "WithParts", newWithPart(alias, sql)).(WithBuilder)
+//
+// Ex:
+//
+//	With().As("active", Select("id").From("users").Where("active"), CTEColumns("user_id"), Materialized())
+//	// WITH active(user_id) AS MATERIALIZED ( SELECT id FROM users WHERE active)
+func (b WithBuilder) As(alias string, sql Sqlizer, opts ...CTEOption) WithBuilder {
+	return builder.Append(b, "WithParts", newWithPart(alias, sql, opts)).(WithBuilder)
+}
+
+// Recursive adds a recursive "... AS (anchor UNION ALL recursive)" part to the
+// WITH clause, and makes the clause a WITH RECURSIVE clause.
+//
+// Ex:
+//
+//	With().Recursive("tree",
+//		Select("id", "parent_id").From("orgs").Where(Eq{"id": 1}),
+//		Select("o.id", "o.parent_id").From("orgs o").Join("tree t ON o.parent_id = t.id"),
+//		CTEColumns("id", "parent_id"),
+//	).Select("id").From("tree")
+//	// WITH RECURSIVE tree(id, parent_id) AS ( SELECT id, parent_id FROM orgs WHERE id = ?
+//	// UNION ALL SELECT o.id, o.parent_id FROM orgs o JOIN tree t ON o.parent_id = t.id)
+//	// SELECT id FROM tree
+func (b WithBuilder) Recursive(alias string, anchor, recursive SelectBuilder, opts ...CTEOption) WithBuilder {
+	return builder.Append(b, "WithParts", newRecursiveWithPart(alias, anchor, recursive, opts)).(WithBuilder)
 }
 
 // PlaceholderFormat sets PlaceholderFormat (e.g. Question or Dollar) for the
@@ -70,55 +224,34 @@ func (b WithBuilder) PlaceholderFormat(f PlaceholderFormat) WithBuilder {
 	return builder.Set(b, "PlaceholderFormat", f).(WithBuilder)
 }
 
-// Select starts a primary SELECT statement for the WITH clause.
-func (b WithBuilder) Select(columns ...string) SelectBuilder {
-	data := builder.GetStruct(b).(withData)
-
-	sql := StatementBuilder.Select(columns...)
-
-	if len(data.WithParts) > 0 {
-		sql = sql.PrefixExpr(&data)
+// Dialect sets the Dialect for the WITH clause and its primary statement, and
+// sets the PlaceholderFormat to the dialect's default. A nil Dialect clears it,
+// keeping the PlaceholderFormat.
+func (b WithBuilder) Dialect(d Dialect) WithBuilder {
+	if d == nil {
+		return builder.Delete(b, "Dialect").(WithBuilder)
 	}
+	b = builder.Set(b, "Dialect", d).(WithBuilder)
+	return b.PlaceholderFormat(d.PlaceholderFormat())
+}
 
-	return sql.PlaceholderFormat(data.PlaceholderFormat)
+// Select starts a primary SELECT statement for the WITH clause. The statement
+// inherits the clause's configuration, e.g. PlaceholderFormat and RunWith.
+func (b WithBuilder) Select(columns ...string) SelectBuilder {
+	return SelectBuilder(b).Columns(columns...)
 }
 
-// Insert starts a primary INSERT statement for the WITH clause.
+// Insert starts a primary INSERT statement for the WITH clause. See Select.
 func (b WithBuilder) Insert(into string) InsertBuilder {
-	data := builder.GetStruct(b).(withData)
-
-	sql := StatementBuilder.Insert(into)
-
-	if len(data.WithParts) > 0 {
-		sql = sql.PrefixExpr(&data)
-	}
-
-	return sql.PlaceholderFormat(data.PlaceholderFormat)
-
+	return InsertBuilder(b).Into(into)
 }
 
-// Update starts a primary UPDATE statement for the WITH clause.
+// Update starts a primary UPDATE statement for the WITH clause. See Select.
 func (b WithBuilder) Update(table string) UpdateBuilder {
-	data := builder.GetStruct(b).(withData)
-
-	sql := StatementBuilder.Update(table)
-
-	if len(data.WithParts) > 0 {
-		sql = sql.PrefixExpr(&data)
-	}
-
-	return sql.PlaceholderFormat(data.PlaceholderFormat)
+	return UpdateBuilder(b).Table(table)
 }
 
-// Delete starts a primary DELETE statement for the WITH clause.
+// Delete starts a primary DELETE statement for the WITH clause. See Select.
 func (b WithBuilder) Delete(from string) DeleteBuilder {
-	data := builder.GetStruct(b).(withData)
-
-	sql := StatementBuilder.Delete(from)
-
-	if len(data.WithParts) > 0 {
-		sql = sql.PrefixExpr(&data)
-	}
-
-	return sql.PlaceholderFormat(data.PlaceholderFormat)
+	return DeleteBuilder(b).From(from)
 }
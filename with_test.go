@@ -1,6 +1,7 @@
 package sq
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -106,3 +107,151 @@ func TestWithBuilder_DeleteOneCTE(t *testing.T) {
 	require.Equal(t, "WITH c AS ( SELECT a FROM b WHERE a = @p1) DELETE FROM d WHERE a IN (SELECT a FROM c)", sql)
 	require.Equal(t, []any{1}, args)
 }
+
+func TestWithBuilder_CTEOptions(t *testing.T) {
+	b := With().
+		As("active", Select("id").From("users").Where("active"), CTEColumns("user_id"), Materialized()).
+		As("recent", Select("id").From("events"), NotMaterialized()).
+		Select("user_id").From("active")
+	sql, _, err := b.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "WITH "+
+		"active(user_id) AS MATERIALIZED ( SELECT id FROM users WHERE active), "+
+		"recent AS NOT MATERIALIZED ( SELECT id FROM events) "+
+		"SELECT user_id FROM active", sql)
+
+	_, _, err = b.Dialect(MySQL).ToSql()
+	require.EqualError(t, err, "MATERIALIZED is not supported by MySQL")
+}
+
+func TestWithBuilder_Recursive(t *testing.T) {
+	w := With().Recursive("tree",
+		Select("id", "parent_id").From("orgs").Where(Eq{"id": 1}),
+		Select("o.id", "o.parent_id").From("orgs o").Join("tree t ON o.parent_id = t.id").Where("o.active = ?", true),
+		CTEColumns("id", "parent_id"),
+		SearchDepthFirst("ord", "id"),
+		Cycle("is_cycle", "path", "id"),
+	)
+
+	sql, args, err := w.Dialect(Postgres).Select("id").From("tree").Where("NOT is_cycle").ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "WITH RECURSIVE tree(id, parent_id) AS ( "+
+		"SELECT id, parent_id FROM orgs WHERE id = $1 "+
+		"UNION ALL SELECT o.id, o.parent_id FROM orgs o JOIN tree t ON o.parent_id = t.id WHERE o.active = $2) "+
+		"SEARCH DEPTH FIRST BY id SET ord CYCLE id SET is_cycle USING path "+
+		"SELECT id FROM tree WHERE NOT is_cycle", sql)
+	require.Equal(t, []any{1, true}, args)
+
+	_, _, err = w.Dialect(SQLite).Select("id").From("tree").ToSql()
+	require.EqualError(t, err, "SEARCH/CYCLE is not supported by SQLite")
+}
+
+func TestWithBuilder_RecursiveKeyword(t *testing.T) {
+	w := With().
+		As("roots", Select("id").From("orgs").Where("parent_id IS NULL")).
+		Recursive("tree",
+			Select("id").From("roots"),
+			Select("o.id").From("orgs o").Join("tree t ON o.parent_id = t.id"),
+			CTEColumns("id"),
+		)
+
+	sql, _, err := w.Select("id").From("tree").ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "WITH RECURSIVE roots AS ( SELECT id FROM orgs WHERE parent_id IS NULL), "+
+		"tree(id) AS ( SELECT id FROM roots UNION ALL SELECT o.id FROM orgs o JOIN tree t ON o.parent_id = t.id) "+
+		"SELECT id FROM tree", sql)
+
+	sql, _, err = w.Dialect(SQLServer).Select("id").From("tree").ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "WITH roots AS ( SELECT id FROM orgs WHERE parent_id IS NULL), "+
+		"tree(id) AS ( SELECT id FROM roots UNION ALL SELECT o.id FROM orgs o JOIN tree t ON o.parent_id = t.id) "+
+		"SELECT id FROM tree", sql)
+}
+
+func TestSelectBuilder_With(t *testing.T) {
+	b := Select("id").
+		With("active", Select("id").From("users").Where("active = ?", true), CTEColumns("id")).
+		From("active").
+		Where("id > ?", 10).
+		PlaceholderFormat(Dollar)
+
+	sql, args, err := b.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "WITH active(id) AS ( SELECT id FROM users WHERE active = $1) SELECT id FROM active WHERE id > $2", sql)
+	require.Equal(t, []any{true, 10}, args)
+
+	sql, args, err = b.RemoveWith().ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT id FROM active WHERE id > $1", sql)
+	require.Equal(t, []any{10}, args)
+}
+
+func TestSelectBuilder_WithAfterPrefix(t *testing.T) {
+	sql, _, err := Select("id").
+		Prefix("/* report */").
+		WithRecursive("tree",
+			Select("id").From("orgs").Where("parent_id IS NULL"),
+			Select("o.id").From("orgs o").Join("tree t ON o.parent_id = t.id"),
+		).
+		From("tree").
+		ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "/* report */ WITH RECURSIVE tree AS ( "+
+		"SELECT id FROM orgs WHERE parent_id IS NULL "+
+		"UNION ALL SELECT o.id FROM orgs o JOIN tree t ON o.parent_id = t.id) "+
+		"SELECT id FROM tree", sql)
+}
+
+func TestStatementBuilders_With(t *testing.T) {
+	sb := StatementBuilder.Dialect(Postgres)
+	cte := Select("id").From("users").Where(Eq{"org_id": 1})
+	in := ConcatExpr("user_id IN (", Select("id").From("c"), ")")
+
+	tests := []struct {
+		name string
+		b    Sqlizer
+		sql  string
+	}{
+		{
+			name: "insert",
+			b:    sb.Insert("archive").Columns("id").Select(Select("id").From("c")).With("c", cte),
+			sql:  "WITH c AS ( SELECT id FROM users WHERE org_id = $1) INSERT INTO archive (id) SELECT id FROM c",
+		},
+		{
+			name: "update",
+			b:    sb.Update("sessions").Set("revoked", true).Where(in).With("c", cte),
+			sql:  "WITH c AS ( SELECT id FROM users WHERE org_id = $1) UPDATE sessions SET revoked = $2 WHERE user_id IN (SELECT id FROM c)",
+		},
+		{
+			name: "delete",
+			b:    sb.Delete("sessions").Where(in).With("c", cte),
+			sql:  "WITH c AS ( SELECT id FROM users WHERE org_id = $1) DELETE FROM sessions WHERE user_id IN (SELECT id FROM c)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sql, _, err := test.b.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.sql, sql)
+		})
+	}
+
+	sql, _, err := Delete("sessions").With("c", cte).RemoveWith().ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "DELETE FROM sessions", sql)
+}
+
+func TestWithBuilder_InheritsConfig(t *testing.T) {
+	db, fake := newFakeDB(nil)
+
+	_, err := StatementBuilder.Dialect(Postgres).RunWith(db).With().
+		As("c", Select("id").From("users").Where("active = ?", true)).
+		Delete("sessions").
+		Where(ConcatExpr("user_id IN (", Select("id").From("c"), ")")).
+		ExecContext(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"WITH c AS ( SELECT id FROM users WHERE active = $1) DELETE FROM sessions WHERE user_id IN (SELECT id FROM c)",
+	}, fake.queries)
+}